@@ -0,0 +1,260 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+// The byte order of the machine we're running on,
+// computed once in init.
+var hostLittleEndian bool
+
+// Bytes returns a []byte which shares its memory
+// with slc. Writes through either slice are visible
+// through the other. The length of the returned
+// slice is len(slc) times the size of slc's
+// element type. If slc is a nil slice, Bytes
+// returns a nil slice.
+//
+// The contents of the returned slice are in the
+// host's byte order; see BytesOrder for a portable
+// alternative.
+//
+// Bytes panics if slc is not a slice value, or if
+// slc's element type is not of fixed size (that is,
+// if it is or contains a pointer, string, slice,
+// map, channel, function, or interface).
+func Bytes(slc interface{}) []byte {
+	slice := reflect.ValueOf(slc)
+	if slice.Kind() != reflect.Slice {
+		panic("illegal.Bytes: passed non-slice value")
+	}
+	elemType := slice.Type().Elem()
+	if !isFixedSize(elemType) {
+		panic("illegal.Bytes: element type " + elemType.String() + " is not of fixed size")
+	}
+	return bytesOf(slice)
+}
+
+// BytesOrder is like Bytes, except that the
+// returned bytes are guaranteed to be in the
+// given byte order. If order matches the host's
+// byte order, the returned slice shares its memory
+// with slc, just as with Bytes. Otherwise, every
+// multi-byte number in slc is byte-swapped into
+// a newly-allocated slice, and writes to it are
+// not visible through slc.
+//
+// BytesOrder panics under the same conditions
+// as Bytes.
+func BytesOrder(slc interface{}, order binary.ByteOrder) []byte {
+	slice := reflect.ValueOf(slc)
+	if slice.Kind() != reflect.Slice {
+		panic("illegal.BytesOrder: passed non-slice value")
+	}
+	elemType := slice.Type().Elem()
+	if !isFixedSize(elemType) {
+		panic("illegal.BytesOrder: element type " + elemType.String() + " is not of fixed size")
+	}
+
+	b := bytesOf(slice)
+	if isLittleEndian(order) == hostLittleEndian || b == nil {
+		return b
+	}
+	ret := make([]byte, len(b))
+	copy(ret, b)
+	swapSlice(ret, elemType)
+	return ret
+}
+
+// FromBytes reinterprets b as a slice whose
+// element type is example's type, and returns
+// that slice. The returned slice shares its memory
+// with b. b is interpreted in the host's byte
+// order; see FromBytesOrder for a portable
+// alternative.
+//
+// FromBytes panics if example's type is not
+// of fixed size (see Bytes), if len(b) is not
+// a multiple of the size of example's type, or
+// if b's first byte is not suitably aligned for
+// example's type.
+//
+// If FromBytes returns without panicking,
+// the return value's underlying value is
+// guaranteed to be a slice, and the element
+// type is guaranteed to be of the same type
+// as example.
+func FromBytes(b []byte, example interface{}) interface{} {
+	typ := reflect.TypeOf(example)
+	if err := checkFromBytes(b, typ); err != "" {
+		panic("illegal.FromBytes: " + err)
+	}
+	if err := checkAlignment(b, typ); err != "" {
+		panic("illegal.FromBytes: " + err)
+	}
+	return fromBytes(b, typ).Interface()
+}
+
+// FromBytesOrder is like FromBytes, except that b
+// is interpreted in the given byte order. If order
+// matches the host's byte order, the returned slice
+// shares its memory with b, just as with FromBytes.
+// Otherwise, b is copied into a newly-allocated
+// slice, and every multi-byte number in it is
+// byte-swapped; in this case, b need not be
+// aligned.
+//
+// FromBytesOrder panics under the same
+// conditions as FromBytes.
+func FromBytesOrder(b []byte, example interface{}, order binary.ByteOrder) interface{} {
+	typ := reflect.TypeOf(example)
+	if err := checkFromBytes(b, typ); err != "" {
+		panic("illegal.FromBytesOrder: " + err)
+	}
+
+	if isLittleEndian(order) == hostLittleEndian {
+		if err := checkAlignment(b, typ); err != "" {
+			panic("illegal.FromBytesOrder: " + err)
+		}
+		return fromBytes(b, typ).Interface()
+	}
+
+	// Allocating a slice of the target type
+	// (rather than of bytes) guarantees that
+	// the copy is properly aligned.
+	ret := reflect.MakeSlice(reflect.SliceOf(typ), len(b)/int(typ.Size()), len(b)/int(typ.Size()))
+	view := bytesOf(ret)
+	copy(view, b)
+	swapSlice(view, typ)
+	return ret.Interface()
+}
+
+// bytesOf assumes that slice is a slice
+// with a fixed-size element type.
+func bytesOf(slice reflect.Value) []byte {
+	n := slice.Len() * int(slice.Type().Elem().Size())
+	return unsafe.Slice((*byte)(slice.UnsafePointer()), n)
+}
+
+// fromBytes assumes that b has passed
+// both checkFromBytes and checkAlignment.
+func fromBytes(b []byte, typ reflect.Type) reflect.Value {
+	n := len(b) / int(typ.Size())
+	if n == 0 {
+		return reflect.MakeSlice(reflect.SliceOf(typ), 0, 0)
+	}
+	return reflect.SliceAt(typ, unsafe.Pointer(unsafe.SliceData(b)), n)
+}
+
+// checkFromBytes and checkAlignment return
+// an error message, or "" if there is no error.
+func checkFromBytes(b []byte, typ reflect.Type) string {
+	if typ == nil || !isFixedSize(typ) {
+		return "type " + typeString(typ) + " is not of fixed size"
+	}
+	if typ.Size() == 0 {
+		return "type " + typ.String() + " has size 0"
+	}
+	if len(b)%int(typ.Size()) != 0 {
+		return "length " + strconv.Itoa(len(b)) + " is not a multiple of the size of " + typ.String() + " (" + strconv.Itoa(int(typ.Size())) + ")"
+	}
+	return ""
+}
+
+func checkAlignment(b []byte, typ reflect.Type) string {
+	if len(b) > 0 && uintptr(unsafe.Pointer(unsafe.SliceData(b)))%uintptr(typ.Align()) != 0 {
+		return "byte slice is not aligned to " + strconv.Itoa(typ.Align()) + " bytes as required by " + typ.String()
+	}
+	return ""
+}
+
+// isFixedSize returns whether values of typ
+// consist only of their own bytes; that is,
+// whether typ neither is nor contains anything
+// which refers to other memory.
+func isFixedSize(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isFixedSize(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if !isFixedSize(typ.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// swapSlice reverses the byte order of every
+// multi-byte number in b, which is interpreted
+// as a slice with element type typ.
+func swapSlice(b []byte, typ reflect.Type) {
+	size := int(typ.Size())
+	for i := 0; i+size <= len(b); i += size {
+		swapValue(b[i:i+size], typ)
+	}
+}
+
+// swapValue reverses the byte order of every
+// multi-byte number in b, which is interpreted
+// as a single value of type typ. Padding bytes
+// are left untouched.
+func swapValue(b []byte, typ reflect.Type) {
+	switch typ.Kind() {
+	case reflect.Complex64, reflect.Complex128:
+		// The real and imaginary parts
+		// are swapped independently.
+		half := len(b) / 2
+		reverse(b[:half])
+		reverse(b[half:])
+	case reflect.Array:
+		swapSlice(b, typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			swapValue(b[f.Offset:f.Offset+f.Type.Size()], f.Type)
+		}
+	default:
+		reverse(b[:typ.Size()])
+	}
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// isLittleEndian works for any binary.ByteOrder,
+// including binary.NativeEndian and user-defined
+// implementations, which cannot be compared
+// directly against binary.LittleEndian.
+func isLittleEndian(order binary.ByteOrder) bool {
+	return order.Uint16([]byte{1, 0}) == 1
+}
+
+func typeString(typ reflect.Type) string {
+	if typ == nil {
+		return "<nil>"
+	}
+	return typ.String()
+}
+
+func init() {
+	x := uint16(1)
+	hostLittleEndian = *(*byte)(unsafe.Pointer(&x)) == 1
+}
@@ -0,0 +1,177 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+type bytesTestStruct struct {
+	A uint16
+	B uint8
+	C uint32
+}
+
+func TestBytes(t *testing.T) {
+	host := hostOrder()
+
+	u32 := []uint32{1, 0xdeadbeef}
+	expect := make([]byte, 8)
+	host.PutUint32(expect[0:], 1)
+	host.PutUint32(expect[4:], 0xdeadbeef)
+	testBytes(u32, expect, nil, t)
+
+	// The result should alias the original slice
+	b := Bytes(u32)
+	b[0], b[1], b[2], b[3] = 0, 0, 0, 0
+	if u32[0] != 0 {
+		t.Errorf("Expected write through Bytes to be visible; got %v", u32[0])
+	}
+
+	testBytes([]uint8{1, 2, 3}, []byte{1, 2, 3}, nil, t)
+	testBytes([]int64(nil), []byte(nil), nil, t)
+	testBytes([][2]int8{{1, 2}, {3, 4}}, []byte{1, 2, 3, 4}, nil, t)
+
+	testBytes(3, nil, "illegal.Bytes: passed non-slice value", t)
+	testBytes([]string{}, nil, "illegal.Bytes: element type string is not of fixed size", t)
+	testBytes([]*int{}, nil, "illegal.Bytes: element type *int is not of fixed size", t)
+	testBytes([]struct{ A []int }{}, nil, "illegal.Bytes: element type struct { A []int } is not of fixed size", t)
+}
+
+func testBytes(slc interface{}, expect []byte, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	b := Bytes(slc)
+	if !reflect.DeepEqual(b, expect) {
+		t.Errorf("Expected %v; got %v", expect, b)
+	}
+}
+
+func TestBytesOrder(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		expect := make([]byte, 8)
+		order.PutUint32(expect[0:], 1)
+		order.PutUint32(expect[4:], 0xdeadbeef)
+		testBytesOrder([]uint32{1, 0xdeadbeef}, order, expect, nil, t)
+
+		expect = make([]byte, 16)
+		order.PutUint64(expect, 0x3ff0000000000000)
+		order.PutUint64(expect[8:], 0x4000000000000000)
+		testBytesOrder([]complex128{complex(1, 2)}, order, expect, nil, t)
+
+		s := bytesTestStruct{0x0102, 0x03, 0x04050607}
+		expect = Bytes([]bytesTestStruct{s})
+		expect = append([]byte(nil), expect...)
+		order.PutUint16(expect[0:], s.A)
+		expect[2] = s.B
+		order.PutUint32(expect[4:], s.C)
+		testBytesOrder([]bytesTestStruct{s}, order, expect, nil, t)
+	}
+
+	testBytesOrder(3, binary.BigEndian, nil, "illegal.BytesOrder: passed non-slice value", t)
+	testBytesOrder([]interface{}{}, binary.BigEndian, nil, "illegal.BytesOrder: element type interface {} is not of fixed size", t)
+}
+
+func testBytesOrder(slc interface{}, order binary.ByteOrder, expect []byte, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	b := BytesOrder(slc, order)
+	if !reflect.DeepEqual(b, expect) {
+		t.Errorf("Expected %v; got %v", expect, b)
+	}
+}
+
+func TestFromBytes(t *testing.T) {
+	host := hostOrder()
+
+	// Allocate as []uint64 to guarantee alignment
+	buf := Bytes(make([]uint64, 2))
+	host.PutUint32(buf[0:], 1)
+	host.PutUint32(buf[4:], 2)
+	host.PutUint32(buf[8:], 3)
+	host.PutUint32(buf[12:], 4)
+	testFromBytes(buf, uint32(0), []uint32{1, 2, 3, 4}, nil, t)
+	testFromBytes(buf[:0], uint32(0), []uint32{}, nil, t)
+	testFromBytes(buf[:4], [2]uint16{}, []([2]uint16){{host.Uint16(buf[0:]), host.Uint16(buf[2:])}}, nil, t)
+
+	// The result should alias the original slice
+	u32 := FromBytes(buf, uint32(0)).([]uint32)
+	u32[0] = 5
+	if host.Uint32(buf) != 5 {
+		t.Errorf("Expected write through FromBytes to be visible; got %v", host.Uint32(buf))
+	}
+
+	testFromBytes(buf[:6], uint32(0), nil, "illegal.FromBytes: length 6 is not a multiple of the size of uint32 (4)", t)
+	testFromBytes(buf[1:5], uint32(0), nil, "illegal.FromBytes: byte slice is not aligned to 4 bytes as required by uint32", t)
+	testFromBytes(buf, "", nil, "illegal.FromBytes: type string is not of fixed size", t)
+	testFromBytes(buf, nil, nil, "illegal.FromBytes: type <nil> is not of fixed size", t)
+	testFromBytes(buf, struct{}{}, nil, "illegal.FromBytes: type struct {} has size 0", t)
+}
+
+func testFromBytes(b []byte, example, expect interface{}, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	result := FromBytes(b, example)
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("Expected %v; got %v", expect, result)
+	}
+}
+
+func TestFromBytesOrder(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		buf := Bytes(make([]uint64, 2))
+		order.PutUint32(buf[0:], 1)
+		order.PutUint32(buf[4:], 0xdeadbeef)
+		testFromBytesOrder(buf[:8], uint32(0), order, []uint32{1, 0xdeadbeef}, nil, t)
+
+		order.PutUint64(buf, 0x4000000000000000)
+		testFromBytesOrder(buf[:8], float64(0), order, []float64{2}, nil, t)
+
+		s := bytesTestStruct{0x0102, 0x03, 0x04050607}
+		testFromBytesOrder(BytesOrder([]bytesTestStruct{s}, order), bytesTestStruct{}, order, []bytesTestStruct{s}, nil, t)
+	}
+
+	buf := Bytes(make([]uint64, 1))
+	testFromBytesOrder(buf[:3], uint16(0), binary.BigEndian, nil, "illegal.FromBytesOrder: length 3 is not a multiple of the size of uint16 (2)", t)
+	testFromBytesOrder(buf[1:3], uint16(0), hostOrder(), nil, "illegal.FromBytesOrder: byte slice is not aligned to 2 bytes as required by uint16", t)
+}
+
+func testFromBytesOrder(b []byte, example interface{}, order binary.ByteOrder, expect interface{}, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	result := FromBytesOrder(b, example, order)
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("Expected %v; got %v", expect, result)
+	}
+}
+
+func hostOrder() binary.ByteOrder {
+	if hostLittleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
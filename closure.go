@@ -0,0 +1,721 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// A ClosureVar is a variable captured by a closure.
+type ClosureVar struct {
+	Name string
+
+	// The variable's current value. If ByRef is
+	// true, Value is addressable, and setting it
+	// modifies the variable as seen by both the
+	// closure and the function which declared it.
+	Value reflect.Value
+
+	// Whether the closure holds a reference to the
+	// variable rather than a copy of it. The
+	// compiler captures a variable by reference
+	// if it is ever reassigned (including by
+	// assigning to one of its fields or array
+	// elements), if its address is taken, if it
+	// is a named result, or if it is larger than
+	// 128 bytes.
+	ByRef bool
+}
+
+// ClosureVars returns the variables captured by
+// fn, in the order in which the compiler lays
+// them out, which is the order in which they are
+// first referenced in fn's source. If fn is not
+// a closure (for example, if it is a top-level
+// function), ClosureVars returns no variables.
+//
+// The memory layout of a closure is not recorded
+// in the binary. Instead, ClosureVars reconstructs
+// it from fn's source, which must be available
+// (see Signature), by type-checking fn's package
+// and mimicking the compiler's rules. Named types
+// of captured variables (other than those built in)
+// must have been registered with RegisterType.
+// Rather than guessing, ClosureVars returns an error
+// if the layout cannot be determined; this happens
+// if the source is unavailable or ambiguous, if a
+// type cannot be resolved, if fn is a method value
+// or is declared in a generic function, or if fn
+// captures a variable declared in a three-clause
+// for loop (whose per-iteration copies the compiler
+// treats specially), or if fn refers to a variable
+// in code which the compiler may remove (such as a
+// branch whose condition is constant, or statements
+// following a return), since a variable referred to
+// only there is not captured at all.
+//
+// The layout is an implementation detail of the gc
+// compiler, which ClosureVars is written against.
+// Other compilers, or future versions of gc, may
+// lay out closures differently, or remove more code
+// than ClosureVars expects. The only check made
+// against the closure itself is of its code pointer,
+// so such differences may go undetected. Inlining
+// can also change the name of a closure, in which
+// case its source cannot be found.
+//
+// ClosureVars panics if fn is not a non-nil function.
+func ClosureVars(fn interface{}) ([]ClosureVar, error) {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func || f.IsNil() {
+		panic("illegal.ClosureVars: passed non-function value")
+	}
+
+	vars, err := closureLayout(Signature(fn))
+	if err != "" {
+		return nil, errors.New("illegal.ClosureVars: could not determine closure layout: " + err)
+	}
+	if len(vars) == 0 {
+		return nil, nil
+	}
+
+	// A func value is a pointer to a struct whose
+	// first field is the function's code pointer,
+	// and whose remaining fields hold the captured
+	// variables (or pointers to them).
+	fields := []reflect.StructField{{Name: "F", Type: reflect.TypeOf(uintptr(0))}}
+	for i, v := range vars {
+		typ := v.Value.Type()
+		if v.ByRef {
+			typ = reflect.PointerTo(typ)
+		}
+		fields = append(fields, reflect.StructField{Name: "X" + strconv.Itoa(i), Type: typ})
+	}
+	closure := (*[2]unsafe.Pointer)(unsafe.Pointer(&fn))[1]
+	layout := reflect.NewAt(reflect.StructOf(fields), closure).Elem()
+	if uintptr(layout.Field(0).Uint()) != f.Pointer() {
+		return nil, errors.New("illegal.ClosureVars: could not determine closure layout: code pointer mismatch")
+	}
+
+	for i := range vars {
+		val := layout.Field(i + 1)
+		if vars[i].ByRef {
+			val = val.Elem()
+		}
+		vars[i].Value = val
+	}
+	return vars, nil
+}
+
+// closureLayout returns the variables captured by
+// the closure described by s, with each Value set
+// to the zero value of the variable's type, or an
+// error message, or "" if there is no error.
+func closureLayout(s *FuncSignature) ([]ClosureVar, string) {
+	if strings.HasSuffix(s.Name, "-fm") {
+		return nil, "method values are not supported"
+	}
+	node := s.findSource()
+	if node == nil {
+		return nil, "could not find source of " + s.Name
+	}
+	lit, ok := node.(*ast.FuncLit)
+	if !ok {
+		return nil, ""
+	}
+
+	file, fset := parseSourceFile(s.File)
+	var decl *ast.FuncDecl
+	for _, d := range file.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Pos() <= lit.Pos() && lit.End() <= fd.End() {
+			decl = fd
+		}
+	}
+	if decl == nil {
+		// Closures declared at package level
+		// can only refer to package-level
+		// variables, which aren't captured
+		return nil, ""
+	}
+	if decl.Type.TypeParams != nil || (decl.Recv != nil && hasTypeParams(decl.Recv.List[0].Type)) {
+		return nil, "closures in generic functions are not supported"
+	}
+
+	info, err := typeCheckPackage(s, file, fset)
+	if err != "" {
+		return nil, err
+	}
+
+	var vars []*types.Var
+	var deadVar *types.Var
+	seen := make(map[*types.Var]bool)
+	dead := deadCode(lit.Body, info)
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		v, ok := info.Uses[id].(*types.Var)
+		if !ok || v.IsField() || v.Pos() < decl.Pos() || v.Pos() >= decl.End() || (lit.Pos() <= v.Pos() && v.Pos() < lit.End()) {
+			return true
+		}
+		if deadVar == nil && dead.contains(id) {
+			deadVar = v
+		}
+		if !seen[v] {
+			seen[v] = true
+			vars = append(vars, v)
+		}
+		return true
+	})
+	if deadVar != nil {
+		return nil, "variable " + deadVar.Name() + " is referred to in code which the compiler may remove"
+	}
+
+	reassigned, addrTaken, loopVars, unknown := assignments(decl, info)
+	ret := make([]ClosureVar, len(vars))
+	for i, v := range vars {
+		if loopVars[v] {
+			return nil, "cannot determine how loop variable " + v.Name() + " is captured"
+		}
+		if unknown[v] {
+			return nil, "cannot determine how variable " + v.Name() + " is captured"
+		}
+		typ, err := reflectType(v.Type())
+		if err != "" {
+			return nil, "variable " + v.Name() + ": " + err
+		}
+		ret[i] = ClosureVar{
+			Name:  v.Name(),
+			Value: reflect.Zero(typ),
+			ByRef: reassigned[v] || addrTaken[v] || typ.Size() > 128,
+		}
+	}
+	return ret, ""
+}
+
+// A nodeSet is a set of syntax trees,
+// identified by their extents.
+type nodeSet []ast.Node
+
+// contains returns whether n is
+// within one of the trees in s.
+func (s nodeSet) contains(n ast.Node) bool {
+	for _, m := range s {
+		if m.Pos() <= n.Pos() && n.End() <= m.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// deadCode returns the parts of body which the
+// compiler may remove before deciding what a
+// closure captures, erring on the side of
+// including too much: the branch not taken by
+// an if statement whose condition is constant;
+// any other if or for statement, or switch
+// statement with a constant (or no) tag, whose
+// condition or one of whose cases depends on a
+// constant; the operands of && and || which
+// follow a constant; and statements which follow
+// one which may terminate their block.
+func deadCode(body *ast.BlockStmt, info *types.Info) nodeSet {
+	var dead nodeSet
+	isConst := func(e ast.Expr) bool {
+		tv, ok := info.Types[e]
+		return !ok || tv.Value != nil
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			if tv := info.Types[n.Cond]; tv.Value != nil {
+				if !constant.BoolVal(tv.Value) {
+					dead = append(dead, n.Body)
+				} else if n.Else != nil {
+					dead = append(dead, n.Else)
+				}
+			} else if dependsOnConst(n.Cond, isConst) {
+				dead = append(dead, n)
+				return false
+			}
+		case *ast.ForStmt:
+			// for true { ... } is kept as it is
+			if tv := info.Types[n.Cond]; n.Cond != nil && dependsOnConst(n.Cond, isConst) && (tv.Value == nil || !constant.BoolVal(tv.Value)) {
+				dead = append(dead, n)
+				return false
+			}
+		case *ast.SwitchStmt:
+			if n.Tag == nil || isConst(n.Tag) {
+				for _, clause := range n.Body.List {
+					for _, e := range clause.(*ast.CaseClause).List {
+						if isConst(e) {
+							dead = append(dead, n)
+							return false
+						}
+					}
+				}
+			}
+		case *ast.BinaryExpr:
+			if (n.Op == token.LAND || n.Op == token.LOR) && dependsOnConst(n.X, isConst) {
+				dead = append(dead, n.Y)
+			}
+		case *ast.BlockStmt:
+			dead = append(dead, unreachable(n.List, isConst)...)
+		case *ast.CaseClause:
+			dead = append(dead, unreachable(n.Body, isConst)...)
+		case *ast.CommClause:
+			dead = append(dead, unreachable(n.Body, isConst)...)
+		}
+		return true
+	})
+	return dead
+}
+
+// dependsOnConst returns whether the boolean
+// expression e, or any operand of !, &&, or ||
+// within it, is constant.
+func dependsOnConst(e ast.Expr, isConst func(ast.Expr) bool) bool {
+	e = ast.Unparen(e)
+	if isConst(e) {
+		return true
+	}
+	switch x := e.(type) {
+	case *ast.UnaryExpr:
+		return x.Op == token.NOT && dependsOnConst(x.X, isConst)
+	case *ast.BinaryExpr:
+		return (x.Op == token.LAND || x.Op == token.LOR) && (dependsOnConst(x.X, isConst) || dependsOnConst(x.Y, isConst))
+	}
+	return false
+}
+
+// unreachable returns the statements in list
+// which follow one which may terminate it.
+func unreachable(list []ast.Stmt, isConst func(ast.Expr) bool) nodeSet {
+	for i, stmt := range list {
+		if mayTerminate(stmt, isConst) {
+			var ret nodeSet
+			for _, s := range list[i+1:] {
+				ret = append(ret, s)
+			}
+			return ret
+		}
+	}
+	return nil
+}
+
+// mayTerminate returns whether stmt may be
+// treated by the compiler as terminating its
+// block: a return, goto, or call to panic, or
+// a block or if statement containing one.
+func mayTerminate(stmt ast.Stmt, isConst func(ast.Expr) bool) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return s.Tok == token.GOTO
+	case *ast.ExprStmt:
+		call, ok := ast.Unparen(s.X).(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+		return ok && id.Name == "panic"
+	case *ast.BlockStmt:
+		return len(s.List) > 0 && mayTerminate(s.List[len(s.List)-1], isConst)
+	case *ast.IfStmt:
+		if !dependsOnConst(s.Cond, isConst) {
+			return mayTerminate(s.Body, isConst) && s.Else != nil && mayTerminate(s.Else, isConst)
+		}
+		return mayTerminate(s.Body, isConst) || (s.Else != nil && mayTerminate(s.Else, isConst))
+	}
+	return false
+}
+
+func hasTypeParams(recv ast.Expr) bool {
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	switch recv.(type) {
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
+
+// typeCheckPackage type-checks the package
+// containing file, which consists of the files
+// in the same directory with the same package
+// clause. Type errors (for example, from files
+// excluded by build constraints) are ignored,
+// since we only need the types of local variables.
+func typeCheckPackage(s *FuncSignature, file *ast.File, fset *token.FileSet) (*types.Info, string) {
+	dir := filepath.Dir(s.File)
+
+	typeChecked.Lock()
+	defer typeChecked.Unlock()
+	if info, ok := typeChecked.infos[dir]; ok {
+		return info, ""
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "could not read source directory: " + err.Error()
+	}
+	files := []*ast.File{file}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if !strings.HasSuffix(e.Name(), ".go") || path == s.File {
+			continue
+		}
+		if f, _ := parseSourceFile(path); f != nil && f.Name.Name == file.Name.Name {
+			files = append(files, f)
+		}
+	}
+
+	// The runtime's name for a function
+	// begins with its package's import path
+	pkgPath := s.Name
+	if i := strings.Index(pkgPath[strings.LastIndex(pkgPath, "/")+1:], "."); i != -1 {
+		pkgPath = pkgPath[:strings.LastIndex(pkgPath, "/")+1+i]
+	}
+
+	if typeChecked.infos == nil {
+		typeChecked.infos = make(map[string]*types.Info)
+		typeChecked.importer = importer.ForCompiler(fset, "source", nil)
+	}
+
+	info := &types.Info{
+		Uses:       make(map[*ast.Ident]types.Object),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+	}
+	conf := types.Config{
+		Importer: typeChecked.importer,
+		Error:    func(error) {},
+	}
+	conf.Check(pkgPath, fset, files, info)
+	typeChecked.infos[dir] = info
+	return info, ""
+}
+
+// Type-checking is expensive (particularly
+// importing dependencies from source), so
+// the results are cached by directory.
+var typeChecked struct {
+	sync.Mutex
+	infos    map[string]*types.Info
+	importer types.Importer
+}
+
+// assignments finds the variables which are
+// reassigned (other than by their declaration,
+// and including assignments to their fields or
+// elements, and named results, which return
+// statements assign) or have their address
+// taken within decl, the variables declared in
+// the init statements of three-clause for loops,
+// and the variables for which this cannot be
+// determined because type information is missing.
+func assignments(decl *ast.FuncDecl, info *types.Info) (reassigned, addrTaken, loopVars, unknown map[*types.Var]bool) {
+	reassigned = make(map[*types.Var]bool)
+	addrTaken = make(map[*types.Var]bool)
+	loopVars = make(map[*types.Var]bool)
+	unknown = make(map[*types.Var]bool)
+
+	varOf := func(e ast.Expr) *types.Var {
+		id, ok := ast.Unparen(e).(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		v, _ := info.Uses[id].(*types.Var)
+		return v
+	}
+
+	// mark records the variable whose address is
+	// taken when e's is (see addressedRoot) in m
+	mark := func(m map[*types.Var]bool, e ast.Expr) {
+		root, ok := addressedRoot(e, info)
+		if !ok {
+			if v := varOf(leftmost(e)); v != nil {
+				unknown[v] = true
+			}
+			return
+		}
+		if v := varOf(root); v != nil {
+			m[v] = true
+		}
+	}
+
+	ast.Inspect(decl, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncType:
+			if n.Results == nil {
+				return true
+			}
+			for _, field := range n.Results.List {
+				for _, name := range field.Names {
+					if v, ok := info.Defs[name].(*types.Var); ok {
+						reassigned[v] = true
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			// Short variable declarations which
+			// redeclare a variable record it in Uses.
+			// Assigning to a field of a struct, or an
+			// element of an array, reassigns it too.
+			for _, lhs := range n.Lhs {
+				mark(reassigned, lhs)
+			}
+		case *ast.IncDecStmt:
+			mark(reassigned, n.X)
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN {
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					if e != nil {
+						mark(reassigned, e)
+					}
+				}
+			}
+		case *ast.ForStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				for _, lhs := range init.Lhs {
+					if v, ok := info.Defs[lhs.(*ast.Ident)].(*types.Var); ok {
+						loopVars[v] = true
+					}
+				}
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND {
+				mark(addrTaken, n.X)
+			}
+		case *ast.SliceExpr:
+			// Slicing an array takes its address
+			tv, ok := info.Types[n.X]
+			if !ok || tv.Type == nil {
+				mark(unknown, n.X)
+			} else if _, ok := tv.Type.Underlying().(*types.Array); ok {
+				mark(addrTaken, n.X)
+			}
+		case *ast.SelectorExpr:
+			// Calling a pointer method on an
+			// addressable value takes its address
+			sel, ok := info.Selections[n]
+			if !ok || sel.Kind() != types.MethodVal {
+				return true
+			}
+			if _, ok := sel.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer); ok {
+				if _, ok := sel.Recv().(*types.Pointer); !ok {
+					mark(addrTaken, n.X)
+				}
+			}
+		}
+		return true
+	})
+	return reassigned, addrTaken, loopVars, unknown
+}
+
+// addressedRoot returns the variable expression
+// whose address is taken when e's address is
+// taken, or which is reassigned when e is (for
+// example, s for s.f or a[1] when s is a struct
+// and a an array). It returns false if the types
+// needed to tell are missing.
+func addressedRoot(e ast.Expr, info *types.Info) (ast.Expr, bool) {
+	for {
+		e = ast.Unparen(e)
+		switch x := e.(type) {
+		case *ast.SelectorExpr:
+			sel, ok := info.Selections[x]
+			if !ok {
+				// A qualified identifier (pkg.Var)
+				// refers to a package-level variable
+				if id, ok := x.X.(*ast.Ident); ok {
+					if _, ok := info.Uses[id].(*types.PkgName); ok {
+						return e, true
+					}
+				}
+				return e, false
+			}
+			if sel.Kind() != types.FieldVal || sel.Indirect() {
+				return e, true
+			}
+			if _, ok := sel.Recv().Underlying().(*types.Pointer); ok {
+				return e, true
+			}
+			e = x.X
+		case *ast.IndexExpr:
+			tv, ok := info.Types[x.X]
+			if !ok || tv.Type == nil {
+				return e, false
+			}
+			if _, ok := tv.Type.Underlying().(*types.Array); !ok {
+				return e, true
+			}
+			e = x.X
+		default:
+			return e, true
+		}
+	}
+}
+
+// leftmost returns the operand at the root of a
+// chain of selectors and index expressions (for
+// example, a for a.b[1].c).
+func leftmost(e ast.Expr) ast.Expr {
+	for {
+		switch x := ast.Unparen(e).(type) {
+		case *ast.SelectorExpr:
+			e = x.X
+		case *ast.IndexExpr:
+			e = x.X
+		default:
+			return x
+		}
+	}
+}
+
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.Bool:          reflect.TypeOf(false),
+	types.Int:           reflect.TypeOf(int(0)),
+	types.Int8:          reflect.TypeOf(int8(0)),
+	types.Int16:         reflect.TypeOf(int16(0)),
+	types.Int32:         reflect.TypeOf(int32(0)),
+	types.Int64:         reflect.TypeOf(int64(0)),
+	types.Uint:          reflect.TypeOf(uint(0)),
+	types.Uint8:         reflect.TypeOf(uint8(0)),
+	types.Uint16:        reflect.TypeOf(uint16(0)),
+	types.Uint32:        reflect.TypeOf(uint32(0)),
+	types.Uint64:        reflect.TypeOf(uint64(0)),
+	types.Uintptr:       reflect.TypeOf(uintptr(0)),
+	types.Float32:       reflect.TypeOf(float32(0)),
+	types.Float64:       reflect.TypeOf(float64(0)),
+	types.Complex64:     reflect.TypeOf(complex64(0)),
+	types.Complex128:    reflect.TypeOf(complex128(0)),
+	types.String:        reflect.TypeOf(""),
+	types.UnsafePointer: reflect.TypeOf(unsafe.Pointer(nil)),
+}
+
+// reflectType converts a type from go/types to
+// a reflect.Type, returning an error message,
+// or "" if there is no error. Named types are
+// resolved using the type registry.
+func reflectType(t types.Type) (reflect.Type, string) {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		if typ, ok := basicTypes[t.Kind()]; ok {
+			return typ, ""
+		}
+	case *types.Named:
+		obj := t.Obj()
+		if t.TypeArgs().Len() > 0 {
+			return nil, "instantiated generic type " + t.String() + " is not supported"
+		}
+		name := obj.Name()
+		if obj.Pkg() != nil {
+			name = obj.Pkg().Path() + "." + name
+		}
+		if typ := TypeByName(name); typ != nil {
+			return typ, ""
+		}
+		return nil, "type " + name + " is not registered (see RegisterType)"
+	case *types.Pointer:
+		elem, err := reflectType(t.Elem())
+		if err != "" {
+			return nil, err
+		}
+		return reflect.PointerTo(elem), ""
+	case *types.Slice:
+		elem, err := reflectType(t.Elem())
+		if err != "" {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), ""
+	case *types.Array:
+		elem, err := reflectType(t.Elem())
+		if err != "" {
+			return nil, err
+		}
+		return reflect.ArrayOf(int(t.Len()), elem), ""
+	case *types.Map:
+		key, err := reflectType(t.Key())
+		if err != "" {
+			return nil, err
+		}
+		elem, err := reflectType(t.Elem())
+		if err != "" {
+			return nil, err
+		}
+		return reflect.MapOf(key, elem), ""
+	case *types.Chan:
+		elem, err := reflectType(t.Elem())
+		if err != "" {
+			return nil, err
+		}
+		dir := reflect.BothDir
+		switch t.Dir() {
+		case types.SendOnly:
+			dir = reflect.SendDir
+		case types.RecvOnly:
+			dir = reflect.RecvDir
+		}
+		return reflect.ChanOf(dir, elem), ""
+	case *types.Signature:
+		in, err := reflectTuple(t.Params())
+		if err != "" {
+			return nil, err
+		}
+		out, err := reflectTuple(t.Results())
+		if err != "" {
+			return nil, err
+		}
+		return reflect.FuncOf(in, out, t.Variadic()), ""
+	case *types.Struct:
+		fields := make([]reflect.StructField, t.NumFields())
+		for i := range fields {
+			f := t.Field(i)
+			if !f.Exported() {
+				return nil, "struct type with unexported field " + f.Name() + " is not supported"
+			}
+			typ, err := reflectType(f.Type())
+			if err != "" {
+				return nil, err
+			}
+			fields[i] = reflect.StructField{Name: f.Name(), Type: typ, Tag: reflect.StructTag(t.Tag(i)), Anonymous: f.Anonymous()}
+		}
+		return reflect.StructOf(fields), ""
+	case *types.Interface:
+		if t.NumMethods() == 0 {
+			return InterfaceType, ""
+		}
+	}
+	return nil, "type " + t.String() + " is not supported"
+}
+
+func reflectTuple(tuple *types.Tuple) ([]reflect.Type, string) {
+	ret := make([]reflect.Type, tuple.Len())
+	for i := range ret {
+		typ, err := reflectType(tuple.At(i).Type())
+		if err != "" {
+			return nil, err
+		}
+		ret[i] = typ
+	}
+	return ret, ""
+}
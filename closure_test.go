@@ -0,0 +1,194 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type ClosureTestType struct {
+	A int
+}
+
+type closureTestUnregistered struct{}
+
+func closureTestCounter(start int) (func() int, *int) {
+	n := start
+	step := 2
+	return func() int {
+		n += step
+		return n
+	}, &n
+}
+
+func TestClosureVars(t *testing.T) {
+	RegisterType(ClosureTestType{})
+
+	// Captured by value: never reassigned
+	a, s, ct := 1, "str", ClosureTestType{3}
+	f := func() string { return s + string(rune(a)) + string(rune(ct.A)) }
+	vars, err := ClosureVars(f)
+	testClosureVars(vars, err, []string{"s", "a", "ct"}, []interface{}{"str", 1, ClosureTestType{3}}, []bool{false, false, false}, nil, t)
+
+	// Captured by reference: reassigned, address
+	// taken, or too large
+	var big [200]byte
+	b, c := 1, 2
+	p := &c
+	g := func() int {
+		b++
+		return b + c + int(big[0]) + *p
+	}
+	vars, err = ClosureVars(g)
+	testClosureVars(vars, err, []string{"b", "c", "big", "p"}, []interface{}{1, 2, big, p}, []bool{true, true, true, false}, nil, t)
+
+	// By-reference values are shared
+	vars[0].Value.SetInt(10)
+	if b != 10 {
+		t.Errorf("Expected setting b through ClosureVars to be visible; got %v", b)
+	}
+
+	next, np := closureTestCounter(5)
+	next()
+	vars, err = ClosureVars(next)
+	testClosureVars(vars, err, []string{"n", "step"}, []interface{}{7, 2}, []bool{true, false}, nil, t)
+	if vars[0].Value.Addr().Interface() != np {
+		t.Errorf("Expected captured n to be the same variable as returned")
+	}
+
+	// Not closures
+	vars, err = ClosureVars(closureTestCounter)
+	testClosureVars(vars, err, nil, nil, nil, nil, t)
+	vars, err = ClosureVars(func() int { return 1 })
+	testClosureVars(vars, err, nil, nil, nil, nil, t)
+
+	// ClosureVars should fail
+	u := closureTestUnregistered{}
+	vars, err = ClosureVars(func() interface{} { return u })
+	testClosureVars(vars, err, nil, nil, nil,
+		"illegal.ClosureVars: could not determine closure layout: variable u: type github.com/joshlf13/illegal.closureTestUnregistered is not registered (see RegisterType)", t)
+	vars, err = ClosureVars(ClosureTestType{}.closureTestMethod)
+	testClosureVars(vars, err, nil, nil, nil, "illegal.ClosureVars: could not determine closure layout: method values are not supported", t)
+	for i := 0; i < 1; i++ {
+		vars, err = ClosureVars(func() int { return i })
+		testClosureVars(vars, err, nil, nil, nil, "illegal.ClosureVars: could not determine closure layout: cannot determine how loop variable i is captured", t)
+	}
+	x, y := func() int { return a }, func() int { return b }
+	vars, err = ClosureVars(x)
+	testClosureVars(vars, err, nil, nil, nil, "illegal.ClosureVars: could not determine closure layout: could not find source of github.com/joshlf13/illegal.TestClosureVars.func6", t)
+	_ = y
+
+	testWrapPanic(func() { ClosureVars(3) }, "illegal.ClosureVars: passed non-function value", t)
+	testWrapPanic(func() { ClosureVars((func())(nil)) }, "illegal.ClosureVars: passed non-function value", t)
+}
+
+func (c ClosureTestType) closureTestMethod() {}
+
+func closureTestResult() (n int, f func() int) {
+	f = func() int { return n }
+	return 3, f
+}
+
+func TestClosureVarsAssignments(t *testing.T) {
+	RegisterType(ClosureTestType{})
+
+	// Assigning to a field or an element reassigns
+	// the variable, so it is captured by reference
+	ct := ClosureTestType{1}
+	arr := [2]int{1, 2}
+	f := func() int { return ct.A + arr[0] + arr[1] }
+	ct.A = 5
+	arr[1]++
+	vars, err := ClosureVars(f)
+	testClosureVars(vars, err, []string{"ct", "arr"}, []interface{}{ClosureTestType{5}, [2]int{1, 3}}, []bool{true, true}, nil, t)
+
+	// Assigning through a pointer or to a slice
+	// element does not reassign the variable
+	p, slc := &ClosureTestType{1}, []int{1}
+	g := func() int { return p.A + slc[0] }
+	p.A, slc[0] = 2, 3
+	vars, err = ClosureVars(g)
+	testClosureVars(vars, err, []string{"p", "slc"}, []interface{}{p, slc}, []bool{false, false}, nil, t)
+
+	// Named results are assigned by return
+	_, h := closureTestResult()
+	vars, err = ClosureVars(h)
+	testClosureVars(vars, err, []string{"n"}, []interface{}{3}, []bool{true}, nil, t)
+}
+
+const closureTestDebug = false
+
+func TestClosureVarsDeadCode(t *testing.T) {
+	// The compiler doesn't capture variables
+	// referred to only in code it removes, so
+	// ClosureVars can't tell the layout
+	a, b := 7, 9
+	f := func() int {
+		if false {
+			return a
+		}
+		return b
+	}
+	vars, err := ClosureVars(f)
+	testClosureVars(vars, err, nil, nil, nil,
+		"illegal.ClosureVars: could not determine closure layout: variable a is referred to in code which the compiler may remove", t)
+
+	c := "c"
+	g := func() int {
+		if closureTestDebug {
+			println(c)
+		}
+		return b
+	}
+	vars, err = ClosureVars(g)
+	testClosureVars(vars, err, nil, nil, nil,
+		"illegal.ClosureVars: could not determine closure layout: variable c is referred to in code which the compiler may remove", t)
+
+	h := func() int {
+		if true {
+			return b
+		}
+		return a
+	}
+	vars, err = ClosureVars(h)
+	testClosureVars(vars, err, nil, nil, nil,
+		"illegal.ClosureVars: could not determine closure layout: variable a is referred to in code which the compiler may remove", t)
+
+	i := func() bool { return closureTestDebug && c == "" }
+	vars, err = ClosureVars(i)
+	testClosureVars(vars, err, nil, nil, nil,
+		"illegal.ClosureVars: could not determine closure layout: variable c is referred to in code which the compiler may remove", t)
+
+	// Conditions which aren't constant are fine
+	j := func() int {
+		if b > 0 {
+			return a
+		}
+		return b
+	}
+	vars, err = ClosureVars(j)
+	testClosureVars(vars, err, []string{"b", "a"}, []interface{}{9, 7}, []bool{false, false}, nil, t)
+}
+
+func testClosureVars(vars []ClosureVar, e error, names []string, values []interface{}, byRef []bool, err interface{}, t *testing.T) {
+	if e != nil || err != nil {
+		if err == nil || e == nil || e.Error() != err {
+			t.Errorf("Expected error %v; got %v", err, e)
+		}
+		return
+	}
+	if len(vars) != len(names) {
+		t.Errorf("Expected %d variables; got %+v", len(names), vars)
+		return
+	}
+	for i, v := range vars {
+		if v.Name != names[i] || !reflect.DeepEqual(v.Value.Interface(), values[i]) || v.ByRef != byRef[i] {
+			t.Errorf("Expected variable %d to be {%v %v %v}; got {%v %v %v}", i, names[i], values[i], byRef[i],
+				v.Name, strings.TrimSpace(v.Value.String()), v.ByRef)
+		}
+	}
+}
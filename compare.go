@@ -0,0 +1,139 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"cmp"
+	"reflect"
+	"strings"
+)
+
+// Compare returns -1 if a < b, 0 if a == b, and
+// +1 if a > b, according to a total order over
+// values of the same type. a and b must have
+// identical types, which must be ordered.
+//
+// The ordered types are those whose underlying
+// types are integers, floating-point numbers,
+// or strings (which are ordered as they are by
+// Go's < operator), and arrays, structs, and
+// pointers whose element or field types are
+// themselves ordered. For floating-point numbers,
+// NaN is considered less than any other value
+// (including -Inf), and equal to NaN; -0 and +0
+// are considered equal. Arrays and structs are
+// ordered lexicographically by element or field.
+// Pointers are ordered by the values they point
+// to, with nil less than any non-nil pointer.
+// Compare does not detect cycles; comparing
+// distinct cyclic data structures may not
+// terminate.
+//
+// Compare panics if a and b are of different
+// types, if either is nil, or if their type
+// is not ordered.
+func Compare(a, b interface{}) int {
+	if a == nil || b == nil {
+		panic("illegal.Compare: passed nil value")
+	}
+	aVal, bVal := reflect.ValueOf(a), reflect.ValueOf(b)
+	if aVal.Type() != bVal.Type() {
+		panic("illegal.Compare: cannot compare values of different types " + aVal.Type().String() + " and " + bVal.Type().String())
+	}
+	if !Ordered(aVal.Type()) {
+		panic("illegal.Compare: type " + aVal.Type().String() + " is not ordered")
+	}
+	return compare(aVal, bVal)
+}
+
+// CompareFunc returns a function which compares
+// two values of type typ as Compare does. Since
+// typ is only checked once, by CompareFunc, it is
+// cheaper than calling Compare repeatedly; in
+// exchange, the returned function does not check
+// the types of its arguments, which must be typ.
+//
+// CompareFunc panics if typ is not ordered.
+func CompareFunc(typ reflect.Type) func(a, b reflect.Value) int {
+	if !Ordered(typ) {
+		panic("illegal.CompareFunc: type " + typ.String() + " is not ordered")
+	}
+	return compare
+}
+
+// Ordered returns whether values of typ can
+// be compared using Compare.
+func Ordered(typ reflect.Type) bool {
+	return isOrdered(typ, make(map[reflect.Type]bool))
+}
+
+// isOrdered keeps track of the types it
+// has seen so that it terminates on
+// recursive types (such as linked list
+// nodes), which are ordered if all of
+// their other components are.
+func isOrdered(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return true
+	}
+	seen[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	case reflect.Array, reflect.Ptr:
+		return isOrdered(typ.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if !isOrdered(typ.Field(i).Type, seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compare assumes that a and b are of
+// the same type, and that it is ordered.
+func compare(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compare(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compare(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Ptr:
+		switch {
+		case a.Pointer() == b.Pointer():
+			return 0
+		case a.IsNil():
+			return -1
+		case b.IsNil():
+			return 1
+		}
+		return compare(a.Elem(), b.Elem())
+	}
+	panic("illegal: internal error: compare called on unordered type " + a.Type().String())
+}
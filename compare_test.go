@@ -0,0 +1,119 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type compareTestNode struct {
+	Val  int
+	Next *compareTestNode
+}
+
+type compareTestStruct struct {
+	name string
+	age  uint8
+}
+
+func TestCompare(t *testing.T) {
+	// Compare should succeed
+	testCompare(1, 2, -1, nil, t)
+	testCompare(2, 2, 0, nil, t)
+	testCompare(int8(-3), int8(-4), 1, nil, t)
+	testCompare(uint64(math.MaxUint64), uint64(0), 1, nil, t)
+	testCompare(uintptr(1), uintptr(2), -1, nil, t)
+	testCompare(IntAlias(3), IntAlias(2), 1, nil, t)
+	testCompare("abc", "abd", -1, nil, t)
+	testCompare("", "", 0, nil, t)
+	testCompare(1.5, 2.5, -1, nil, t)
+	testCompare(float32(2.5), float32(1.5), 1, nil, t)
+	testCompare(math.NaN(), math.Inf(-1), -1, nil, t)
+	testCompare(math.Inf(-1), math.NaN(), 1, nil, t)
+	testCompare(math.NaN(), math.NaN(), 0, nil, t)
+	testCompare(math.Copysign(0, -1), 0.0, 0, nil, t)
+	testCompare([3]int{1, 2, 3}, [3]int{1, 2, 4}, -1, nil, t)
+	testCompare([3]int{1, 3, 0}, [3]int{1, 2, 4}, 1, nil, t)
+	testCompare([0]int{}, [0]int{}, 0, nil, t)
+	testCompare(compareTestStruct{"a", 30}, compareTestStruct{"a", 20}, 1, nil, t)
+	testCompare(compareTestStruct{"a", 30}, compareTestStruct{"b", 20}, -1, nil, t)
+	testCompare(struct{}{}, struct{}{}, 0, nil, t)
+
+	one, two := 1, 2
+	testCompare(&one, &two, -1, nil, t)
+	testCompare(&two, &one, 1, nil, t)
+	testCompare(&one, &one, 0, nil, t)
+	testCompare((*int)(nil), &one, -1, nil, t)
+	testCompare(&one, (*int)(nil), 1, nil, t)
+	testCompare((*int)(nil), (*int)(nil), 0, nil, t)
+
+	l1 := &compareTestNode{1, &compareTestNode{2, nil}}
+	l2 := &compareTestNode{1, &compareTestNode{3, nil}}
+	l3 := &compareTestNode{1, nil}
+	testCompare(l1, l2, -1, nil, t)
+	testCompare(l1, l3, 1, nil, t)
+	testCompare(*l1, *l1, 0, nil, t)
+
+	// Compare should panic
+	testCompare(nil, 1, 0, "illegal.Compare: passed nil value", t)
+	testCompare(1, nil, 0, "illegal.Compare: passed nil value", t)
+	testCompare(1, int64(1), 0, "illegal.Compare: cannot compare values of different types int and int64", t)
+	testCompare(1, IntAlias(1), 0, "illegal.Compare: cannot compare values of different types int and illegal.IntAlias", t)
+	testCompare(true, false, 0, "illegal.Compare: type bool is not ordered", t)
+	testCompare(1i, 2i, 0, "illegal.Compare: type complex128 is not ordered", t)
+	testCompare([]int{}, []int{}, 0, "illegal.Compare: type []int is not ordered", t)
+	testCompare([0]map[int]int{}, [0]map[int]int{}, 0, "illegal.Compare: type [0]map[int]int is not ordered", t)
+	testCompare(struct{ A func() }{}, struct{ A func() }{}, 0, "illegal.Compare: type struct { A func() } is not ordered", t)
+}
+
+func testCompare(a, b interface{}, expect int, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	c := Compare(a, b)
+	if c != expect {
+		t.Errorf("Expected Compare(%v, %v) to be %v; got %v", a, b, expect, c)
+	}
+}
+
+func TestCompareFunc(t *testing.T) {
+	f := CompareFunc(reflect.TypeOf(compareTestNode{}))
+	a := compareTestNode{1, &compareTestNode{2, nil}}
+	b := compareTestNode{1, nil}
+	if c := f(reflect.ValueOf(a), reflect.ValueOf(b)); c != 1 {
+		t.Errorf("Expected 1; got %v", c)
+	}
+
+	defer func() {
+		if r := recover(); r != "illegal.CompareFunc: type []int is not ordered" {
+			t.Errorf("Expected panic for unordered type; got %v", r)
+		}
+	}()
+	CompareFunc(reflect.TypeOf([]int{}))
+}
+
+func TestOrdered(t *testing.T) {
+	testOrdered(reflect.TypeOf(0), true, t)
+	testOrdered(reflect.TypeOf(compareTestNode{}), true, t)
+	testOrdered(reflect.TypeOf([2]*string{}), true, t)
+	testOrdered(reflect.TypeOf(false), false, t)
+	testOrdered(InterfaceType, false, t)
+	testOrdered(reflect.TypeOf(struct {
+		A int
+		B chan int
+	}{}), false, t)
+}
+
+func testOrdered(typ reflect.Type, expect bool, t *testing.T) {
+	if Ordered(typ) != expect {
+		t.Errorf("Expected Ordered(%v) to be %v; got %v", typ, expect, !expect)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A Dispatcher selects which of a set of functions
+// to call based on the dynamic types of the arguments
+// it is called with (known as multiple dispatch).
+// The zero value is an empty Dispatcher ready to use.
+// A Dispatcher is safe for concurrent use.
+type Dispatcher struct {
+	mu    sync.RWMutex
+	funcs []reflect.Value
+}
+
+// The ways an argument can match a parameter,
+// from most to least specific.
+const (
+	matchExact = iota
+	matchAssignable
+	matchConvertible
+	noMatch
+)
+
+// Register adds fn, which must be a non-variadic
+// function, to the set of functions which d can
+// dispatch to. Registering a function which is
+// already registered has no effect; note that,
+// as with FuncEqual, two closures created from
+// the same function literal are considered to
+// be the same function.
+//
+// Register panics if fn is not a function, if
+// it is variadic, or if a different function
+// with the same parameter types is already
+// registered.
+func (d *Dispatcher) Register(fn interface{}) {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Dispatcher.Register: passed non-function value")
+	}
+	fType := f.Type()
+	if fType.IsVariadic() {
+		panic("illegal.Dispatcher.Register: cannot register variadic function " + fType.String())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, g := range d.funcs {
+		if !sameParams(fType, g.Type()) {
+			continue
+		}
+		if g.Type() == fType && g.Pointer() == f.Pointer() {
+			return
+		}
+		panic("illegal.Dispatcher.Register: a function with parameter types " + paramString(fType) + " is already registered")
+	}
+	d.funcs = append(d.funcs, f)
+}
+
+// Call calls the registered function which most
+// specifically matches the dynamic types of args,
+// and returns its results. Each argument matches
+// its parameter exactly (if its type is identical
+// to the parameter's), by assignability (including
+// implementing an interface parameter), or by
+// convertibility (following the same rules as
+// Invoke), in decreasing order of specificity.
+// A nil argument matches any parameter whose type
+// can be nil by assignability.
+//
+// A function is chosen if, for every argument, it
+// matches at least as specifically as any other
+// matching function. If no function matches, or
+// if no single function is most specific, Call
+// returns an error listing, in the order in which
+// they were registered, the matching functions
+// which no other is more specific than.
+//
+// If the chosen function panics, the panic is
+// not recovered.
+func (d *Dispatcher) Call(args ...interface{}) ([]interface{}, error) {
+	d.mu.RLock()
+	var funcs []reflect.Value
+	var scores [][]int
+	for _, f := range d.funcs {
+		if score, ok := matchArgs(f.Type(), args); ok {
+			funcs = append(funcs, f)
+			scores = append(scores, score)
+		}
+	}
+	d.mu.RUnlock()
+
+	// The candidates are the matching functions which
+	// no other matching function is more specific than.
+	// Comparing every pair (rather than keeping a
+	// running best) keeps the result independent of
+	// the order in which functions were registered.
+	var candidates []reflect.Value
+	for i, f := range funcs {
+		beaten := false
+		for j := range funcs {
+			if dominates(scores[j], scores[i]) {
+				beaten = true
+				break
+			}
+		}
+		if !beaten {
+			candidates = append(candidates, f)
+		}
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return nil, errors.New("illegal.Dispatcher.Call: no function matches argument types " + argString(args))
+	case len(candidates) > 1:
+		names := make([]string, len(candidates))
+		for i, f := range candidates {
+			names[i] = f.Type().String()
+		}
+		return nil, errors.New("illegal.Dispatcher.Call: ambiguous call with argument types " + argString(args) +
+			": candidates are " + strings.Join(names, ", "))
+	}
+
+	best := candidates[0]
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		in[i], _, _ = convertArg(arg, best.Type().In(i))
+	}
+	out := best.Call(in)
+	ret := make([]interface{}, len(out))
+	for i, o := range out {
+		ret[i] = o.Interface()
+	}
+	return ret, nil
+}
+
+// matchArgs returns how specifically each
+// argument matches fType's parameters, or
+// false if any argument doesn't match.
+func matchArgs(fType reflect.Type, args []interface{}) ([]int, bool) {
+	if fType.NumIn() != len(args) {
+		return nil, false
+	}
+	score := make([]int, len(args))
+	for i, arg := range args {
+		score[i] = matchArg(arg, fType.In(i))
+		if score[i] == noMatch {
+			return nil, false
+		}
+	}
+	return score, true
+}
+
+func matchArg(arg interface{}, typ reflect.Type) int {
+	if arg == nil {
+		if _, _, ok := convertArg(nil, typ); ok {
+			return matchAssignable
+		}
+		return noMatch
+	}
+	argType := reflect.TypeOf(arg)
+	switch {
+	case argType == typ:
+		return matchExact
+	case argType.AssignableTo(typ):
+		return matchAssignable
+	}
+	if _, _, ok := convertArg(arg, typ); ok {
+		return matchConvertible
+	}
+	return noMatch
+}
+
+// dominates returns whether a is strictly
+// more specific than b.
+func dominates(a, b []int) bool {
+	strict := false
+	for i := range a {
+		if a[i] > b[i] {
+			return false
+		}
+		if a[i] < b[i] {
+			strict = true
+		}
+	}
+	return strict
+}
+
+func sameParams(a, b reflect.Type) bool {
+	if a.NumIn() != b.NumIn() {
+		return false
+	}
+	for i := 0; i < a.NumIn(); i++ {
+		if a.In(i) != b.In(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func paramString(fType reflect.Type) string {
+	params := make([]string, fType.NumIn())
+	for i := range params {
+		params[i] = fType.In(i).String()
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
+func argString(args []interface{}) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = typeString(reflect.TypeOf(arg))
+	}
+	return "(" + strings.Join(types, ", ") + ")"
+}
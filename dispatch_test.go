@@ -0,0 +1,124 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type dispatchTestShape interface {
+	Area() float64
+}
+
+type dispatchTestCircle struct{ R float64 }
+
+func (c dispatchTestCircle) Area() float64 { return 3 * c.R * c.R }
+
+type dispatchTestCircle2 struct{ R float64 }
+
+type dispatchTestSquare struct{ S float64 }
+
+func (s dispatchTestSquare) Area() float64 { return s.S * s.S }
+
+func TestDispatcher(t *testing.T) {
+	var d Dispatcher
+	d.Register(func(c dispatchTestCircle, s dispatchTestSquare) string { return "circle, square" })
+	d.Register(func(a, b dispatchTestShape) string { return "shape, shape" })
+	d.Register(func(c dispatchTestCircle, s dispatchTestShape) string { return "circle, shape" })
+	d.Register(func(s dispatchTestShape, c dispatchTestCircle) string { return "shape, circle" })
+	d.Register(func(i int64) string { return "int64" })
+	d.Register(func(s fmt.Stringer) string { return "stringer" })
+	d.Register(func(e error) string { return "error" })
+	d.Register(func(b []byte) string { return "bytes" })
+	d.Register(func(a [3]int) string { return "array" })
+
+	// Re-registration should be a no-op
+	f := func(i int) string { return "int" }
+	d.Register(f)
+	d.Register(f)
+
+	// Call should succeed
+	testDispatch(&d, []interface{}{dispatchTestCircle{}, dispatchTestSquare{}}, "circle, square", nil, t)
+	testDispatch(&d, []interface{}{dispatchTestSquare{}, dispatchTestSquare{}}, "shape, shape", nil, t)
+	testDispatch(&d, []interface{}{dispatchTestCircle{}, dispatchTestShape(nil)}, "circle, shape", nil, t)
+	testDispatch(&d, []interface{}{dispatchTestSquare{}, dispatchTestCircle{}}, "shape, circle", nil, t)
+	testDispatch(&d, []interface{}{int64(1)}, "int64", nil, t)
+	testDispatch(&d, []interface{}{1}, "int", nil, t)
+	testDispatch(&d, []interface{}{"ab"}, "bytes", nil, t)
+	testDispatch(&d, []interface{}{[]int{1, 2, 3}}, "array", nil, t)
+
+	// Call should fail
+	testDispatch(&d, []interface{}{"a", "b", "c"}, nil, "illegal.Dispatcher.Call: no function matches argument types (string, string, string)", t)
+	testDispatch(&d, []interface{}{[]int{1, 2}}, nil, "illegal.Dispatcher.Call: no function matches argument types ([]int)", t)
+	testDispatch(&d, []interface{}{dispatchTestCircle{}, dispatchTestCircle{}}, nil,
+		"illegal.Dispatcher.Call: ambiguous call with argument types (illegal.dispatchTestCircle, illegal.dispatchTestCircle): "+
+			"candidates are func(illegal.dispatchTestCircle, illegal.dispatchTestShape) string, func(illegal.dispatchTestShape, illegal.dispatchTestCircle) string", t)
+	testDispatch(&d, []interface{}{IntAlias(1)}, nil,
+		"illegal.Dispatcher.Call: ambiguous call with argument types (illegal.IntAlias): candidates are func(int64) string, func(int) string", t)
+	testDispatch(&d, []interface{}{nil}, nil,
+		"illegal.Dispatcher.Call: ambiguous call with argument types (<nil>): candidates are func(fmt.Stringer) string, func(error) string, func([]uint8) string", t)
+
+	// Register should panic
+	testRegister(&d, 3, "illegal.Dispatcher.Register: passed non-function value", t)
+	testRegister(&d, fmt.Sprintf, "illegal.Dispatcher.Register: cannot register variadic function func(string, ...interface {}) string", t)
+	testRegister(&d, func(i int) {}, "illegal.Dispatcher.Register: a function with parameter types (int) is already registered", t)
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	funcs := []interface{}{
+		func(a, b dispatchTestShape) string { return "shape, shape" },
+		func(c dispatchTestCircle, c2 dispatchTestCircle2) string { return "circle, circle2" },
+		func(s dispatchTestShape, c dispatchTestCircle) string { return "shape, circle" },
+	}
+	names := []string{
+		"func(illegal.dispatchTestShape, illegal.dispatchTestShape) string",
+		"func(illegal.dispatchTestCircle, illegal.dispatchTestCircle2) string",
+		"func(illegal.dispatchTestShape, illegal.dispatchTestCircle) string",
+	}
+
+	// The second and third functions are each more
+	// specific than the other for one argument, and
+	// the third beats the first, so the call is
+	// ambiguous regardless of registration order
+	for _, order := range [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+		var d Dispatcher
+		var candidates []string
+		for _, i := range order {
+			d.Register(funcs[i])
+			if i != 0 {
+				candidates = append(candidates, names[i])
+			}
+		}
+		testDispatch(&d, []interface{}{dispatchTestCircle{}, dispatchTestCircle{}}, nil,
+			"illegal.Dispatcher.Call: ambiguous call with argument types (illegal.dispatchTestCircle, illegal.dispatchTestCircle): "+
+				"candidates are "+candidates[0]+", "+candidates[1], t)
+		testDispatch(&d, []interface{}{dispatchTestSquare{}, dispatchTestCircle{}}, "shape, circle", nil, t)
+	}
+}
+
+func testDispatch(d *Dispatcher, args []interface{}, expect interface{}, err interface{}, t *testing.T) {
+	ret, e := d.Call(args...)
+	if e != nil || err != nil {
+		if err == nil || e == nil || e.Error() != err {
+			t.Errorf("Expected error %v; got %v", err, e)
+		}
+		return
+	}
+	if !reflect.DeepEqual(ret, []interface{}{expect}) {
+		t.Errorf("Expected [%v]; got %v", expect, ret)
+	}
+}
+
+func testRegister(d *Dispatcher, fn interface{}, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+	d.Register(fn)
+}
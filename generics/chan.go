@@ -0,0 +1,177 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"context"
+	"reflect"
+)
+
+// The functions in this file stream values from one
+// channel to another through a goroutine. The goroutine
+// exits, closing its output channel, once its input
+// channel has been closed and drained, or once ctx is
+// done; in the latter case, any value which has been
+// received but not yet sent is dropped. Until then, it
+// blocks unless the output channel is drained, so
+// callers which stop receiving early should cancel ctx.
+//
+// Since pred is called on the goroutine, a panic in
+// pred cannot be recovered by the caller. For the
+// same reason, the arguments are checked before the
+// goroutine is started: if ctx is nil, these
+// functions (including their E variants) panic on
+// the calling goroutine, as the context package
+// does, and if buf is negative, the E variants
+// return an *Error of kind NegativeBuffer.
+
+//	func MapChan(ctx context.Context, ch <-chan T, pred func(T) U, buf int) <-chan U
+//
+// MapChan applies pred to each value received
+// from ch, and sends the results, in order, on
+// the returned channel, whose buffer size is buf.
+//
+// ch may be of type chan T or <-chan T.
+func MapChan(ctx context.Context, ch, pred interface{}, buf int) interface{} {
+	ret, err := MapChanE(ctx, ch, pred, buf)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func MapChanE(ctx context.Context, ch <-chan T, pred func(T) U, buf int) (<-chan U, error)
+//
+// MapChanE is like MapChan, except that it returns
+// an *Error rather than panicking if the types of
+// its arguments do not match.
+func MapChanE(ctx context.Context, ch, pred interface{}, buf int) (interface{}, error) {
+	if ctx == nil {
+		panic(packageNamePrefix + "MapChan: nil Context")
+	}
+
+	in, ok := recvChan(ch)
+	if !ok {
+		return nil, newError("MapChan", NotChan, ch, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("MapChan", NotFunction, ch, pred, nil)
+	}
+
+	fType := f.Type()
+
+	sig := mapSig(chanElem(in))
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("MapChan", SignatureMismatch, ch, pred, nil).because(sig, reason)
+	}
+
+	if buf < 0 {
+		return nil, newError("MapChan", NegativeBuffer, ch, pred, nil)
+	}
+
+	out := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, fType.Out(0)), buf)
+	go stream(ctx, in, out, func(v reflect.Value) (reflect.Value, bool) {
+		return f.Call([]reflect.Value{v})[0], true
+	})
+	return out.Convert(reflect.ChanOf(reflect.RecvDir, fType.Out(0))).Interface(), nil
+}
+
+//	func FilterChan(ctx context.Context, ch <-chan T, pred func(T) bool, buf int) <-chan T
+//
+// FilterChan applies pred to each value received
+// from ch, and sends those values for which pred
+// returned true, in order, on the returned channel,
+// whose buffer size is buf.
+//
+// ch may be of type chan T or <-chan T.
+func FilterChan(ctx context.Context, ch, pred interface{}, buf int) interface{} {
+	ret, err := FilterChanE(ctx, ch, pred, buf)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FilterChanE(ctx context.Context, ch <-chan T, pred func(T) bool, buf int) (<-chan T, error)
+//
+// FilterChanE is like FilterChan, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func FilterChanE(ctx context.Context, ch, pred interface{}, buf int) (interface{}, error) {
+	if ctx == nil {
+		panic(packageNamePrefix + "FilterChan: nil Context")
+	}
+
+	in, ok := recvChan(ch)
+	if !ok {
+		return nil, newError("FilterChan", NotChan, ch, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("FilterChan", NotFunction, ch, pred, nil)
+	}
+
+	sig := predicateSig(chanElem(in))
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("FilterChan", SignatureMismatch, ch, pred, nil).because(sig, reason)
+	}
+
+	if buf < 0 {
+		return nil, newError("FilterChan", NegativeBuffer, ch, pred, nil)
+	}
+
+	elemType := in.Type().Elem()
+	out := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, elemType), buf)
+	go stream(ctx, in, out, func(v reflect.Value) (reflect.Value, bool) {
+		return v, f.Call([]reflect.Value{v})[0].Bool()
+	})
+	return out.Convert(reflect.ChanOf(reflect.RecvDir, elemType)).Interface(), nil
+}
+
+// stream receives values from in until it is
+// closed or ctx is done, and sends the results
+// of f for which f returns true on out, which
+// it closes before returning.
+func stream(ctx context.Context, in, out reflect.Value, f func(reflect.Value) (reflect.Value, bool)) {
+	defer out.Close()
+	done := reflect.ValueOf(ctx.Done())
+	recv := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: in},
+		{Dir: reflect.SelectRecv, Chan: done},
+	}
+	send := []reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: out},
+		{Dir: reflect.SelectRecv, Chan: done},
+	}
+	for {
+		chosen, v, ok := reflect.Select(recv)
+		if chosen == 1 || !ok {
+			return
+		}
+		v, ok = f(v)
+		if !ok {
+			continue
+		}
+		send[0].Send = v
+		if chosen, _, _ := reflect.Select(send); chosen == 1 {
+			return
+		}
+	}
+}
+
+// recvChan returns ch as a reflect.Value if it
+// is a channel which allows receiving.
+func recvChan(ch interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+		return v, false
+	}
+	return v, true
+}
+
+func chanElem(ch reflect.Value) sigParam { return concrete(ch.Type().Elem(), "channel element") }
@@ -0,0 +1,132 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMapChan(t *testing.T) {
+	ctx := context.Background()
+
+	// MapChan should succeed
+	testChan(func() interface{} { return MapChan(ctx, sendInts(1, 2, 3), strconv.Itoa, 0) }, []string{"1", "2", "3"}, nil, t)
+	testChan(func() interface{} { return MapChan(ctx, recvOnly(sendInts(1, 2)), strconv.Itoa, 5) }, []string{"1", "2"}, nil, t)
+	testChan(func() interface{} { return MapChan(ctx, sendInts(), strconv.Itoa, 0) }, []string{}, nil, t)
+	testChan(func() interface{} {
+		return MapChan(ctx, sendInts(1, 2), func(i interface{}) bool { return i == 2 }, 0)
+	}, []bool{false, true}, nil, t)
+
+	// MapChan should panic
+	testChan(func() interface{} { return MapChan(ctx, []int{}, strconv.Itoa, 0) }, nil, mapChanChanError, t)
+	testChan(func() interface{} { return MapChan(ctx, make(chan<- int), strconv.Itoa, 0) }, nil, mapChanChanError, t)
+	testChan(func() interface{} { return MapChan(ctx, sendInts(), 3, 0) }, nil, mapChanFunctionError, t)
+	testChan(func() interface{} { return MapChan(ctx, sendInts(), func(s string) int { return 0 }, 0) }, nil,
+		mapChanErrorPrefix+"expected func(int) U, got func(string) int: parameter 0 is string, channel element is int", t)
+	testChan(func() interface{} { return MapChan(ctx, sendInts(), strconv.Itoa, -1) }, nil, mapChanBufferError, t)
+	testChan(func() interface{} { return MapChan(nil, sendInts(), strconv.Itoa, 0) }, nil, "generics.MapChan: nil Context", t)
+
+	// The returned channel's buffer size
+	// should be the one requested
+	out := MapChan(ctx, make(chan int), strconv.Itoa, 4).(<-chan string)
+	if cap(out) != 4 {
+		t.Errorf("Expected capacity 4; got %v", cap(out))
+	}
+}
+
+func TestFilterChan(t *testing.T) {
+	ctx := context.Background()
+	isEven := func(i int) bool { return i%2 == 0 }
+
+	// FilterChan should succeed
+	testChan(func() interface{} { return FilterChan(ctx, sendInts(1, 2, 3, 4), isEven, 0) }, []int{2, 4}, nil, t)
+	testChan(func() interface{} { return FilterChan(ctx, sendInts(1, 3), isEven, 1) }, []int{}, nil, t)
+
+	// FilterChan should panic
+	testChan(func() interface{} { return FilterChan(ctx, nil, isEven, 0) }, nil, filterChanChanError, t)
+	testChan(func() interface{} { return FilterChan(ctx, sendInts(), nil, 0) }, nil, filterChanFunctionError, t)
+	testChan(func() interface{} { return FilterChan(ctx, sendInts(), strconv.Itoa, 0) }, nil,
+		filterChanErrorPrefix+"expected func(int) bool, got func(int) string: result is string, want bool", t)
+	testChan(func() interface{} { return FilterChan(ctx, sendInts(), isEven, -2) }, nil, filterChanBufferError, t)
+	testChan(func() interface{} { return FilterChan(nil, sendInts(), isEven, 0) }, nil, "generics.FilterChan: nil Context", t)
+}
+
+func TestChanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	out := MapChan(ctx, in, strconv.Itoa, 0).(<-chan string)
+
+	in <- 1
+	if s := <-out; s != "1" {
+		t.Errorf("Expected 1; got %v", s)
+	}
+
+	// The goroutine is now blocked receiving from
+	// in (or, below, sending on out); canceling
+	// should make it close out either way
+	in <- 2
+	cancel()
+	select {
+	case <-closed(out):
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected output channel to be closed after cancellation")
+	}
+}
+
+// closed returns a channel which is closed once
+// out is closed, discarding any values sent on
+// out until then.
+func closed(out <-chan string) <-chan struct{} {
+	c := make(chan struct{})
+	go func() {
+		for range out {
+		}
+		close(c)
+	}()
+	return c
+}
+
+func sendInts(ints ...int) chan int {
+	c := make(chan int, len(ints))
+	for _, i := range ints {
+		c <- i
+	}
+	close(c)
+	return c
+}
+
+func recvOnly(c chan int) <-chan int { return c }
+
+// testChan calls f, and checks that the channel
+// it returns receives the values in expect (a
+// slice) before being closed.
+func testChan(f func() interface{}, expect interface{}, err interface{}, t *testing.T) {
+	defer func() {
+		r := errorString(recover())
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	ch := reflect.ValueOf(f())
+	if ch.Type().ChanDir() != reflect.RecvDir {
+		t.Errorf("Expected receive-only channel; got %v", ch.Type())
+	}
+	ret := reflect.MakeSlice(reflect.TypeOf(expect), 0, 0)
+	for {
+		v, ok := ch.Recv()
+		if !ok {
+			break
+		}
+		ret = reflect.Append(ret, v)
+	}
+	if !reflect.DeepEqual(ret.Interface(), expect) {
+		t.Errorf("Expected values %v; got %v", expect, ret)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// An ErrorKind describes what was wrong
+// with the arguments to a function.
+type ErrorKind int
+
+const (
+	// The sequence argument (slc) was not a slice,
+	// array, non-nil pointer to an array, string,
+	// or non-nil iterator function.
+	NotSlice ErrorKind = iota + 1
+	// The function argument (pred or less)
+	// was not a function.
+	NotFunction
+	// The function's signature did not match
+	// its generic type given the element type.
+	SignatureMismatch
+	// The type of zero did not match the
+	// function's result type (Foldr, Foldl, and
+	// Pipeline.Reduce).
+	ZeroMismatch
+	// less was nil, but the element type has
+	// no natural order (Max and Min).
+	NotOrdered
+	// The result type argument was neither a
+	// pointer nor a reflect.Type (MapTo).
+	NotType
+	// The map argument (m) was not a map or a
+	// non-nil iterator function over pairs, or,
+	// for MapValues and FilterMap, was one over
+	// pairs whose key type is not comparable.
+	NotMap
+	// The channel argument (ch) was not a
+	// channel which allows receiving.
+	NotChan
+	// The buffer size argument (buf) was
+	// negative (MapChan and FilterChan).
+	NegativeBuffer
+)
+
+var errorKindStrings = [...]string{
+	NotSlice:          "NotSlice",
+	NotFunction:       "NotFunction",
+	SignatureMismatch: "SignatureMismatch",
+	ZeroMismatch:      "ZeroMismatch",
+	NotOrdered:        "NotOrdered",
+	NotType:           "NotType",
+	NotMap:            "NotMap",
+	NotChan:           "NotChan",
+	NegativeBuffer:    "NegativeBuffer",
+}
+
+func (k ErrorKind) String() string {
+	if k <= 0 || int(k) >= len(errorKindStrings) {
+		return "ErrorKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return errorKindStrings[k]
+}
+
+// An Error is the value with which the functions
+// in this package panic, and which the E variants
+// (MapE, FilterE, and so on) return, when the
+// types of their arguments do not match.
+type Error struct {
+	// The function which was called, without
+	// the package name or E suffix (for
+	// example, "Map" for both Map and MapE).
+	Func string
+	Kind ErrorKind
+
+	// The types of the arguments; any may be
+	// nil if the corresponding argument was nil
+	// or is not taken by Func. For functions
+	// which take a map (m) or a channel (ch),
+	// Slice is its type. If MapTo's result
+	// argument is a reflect.Type, Result is
+	// that type.
+	Slice  reflect.Type
+	Pred   reflect.Type
+	Zero   reflect.Type
+	Result reflect.Type
+
+	// For SignatureMismatch and ZeroMismatch,
+	// the signature which Pred should have had,
+	// with the type parameters which are fixed
+	// by the other arguments filled in (such
+	// as "func(int) U" for Map over a []int),
+	// and a description of the differences
+	// (such as "parameter 0 is string, slice
+	// element is int"). Both are empty for
+	// other kinds of error.
+	Want   string
+	Reason string
+
+	// For errors in a Pipeline (Func is "Stream"),
+	// the position in the chain of the stage whose
+	// argument was mismatched, counting from 1 for
+	// the first stage after Stream, and that
+	// stage's method (such as "Map"). Stage is 0
+	// if the argument to Stream itself, or to a
+	// function which is not part of a Pipeline,
+	// was mismatched.
+	Stage     int
+	StageFunc string
+}
+
+func newError(fname string, kind ErrorKind, slc, pred, zero interface{}) *Error {
+	return &Error{
+		Func:  fname,
+		Kind:  kind,
+		Slice: reflect.TypeOf(slc),
+		Pred:  reflect.TypeOf(pred),
+		Zero:  reflect.TypeOf(zero),
+	}
+}
+
+// withResult sets e's Result to the type of
+// result (MapTo's last argument), or to result
+// itself if it is a reflect.Type, and returns e.
+func (e *Error) withResult(result interface{}) *Error {
+	if typ, ok := result.(reflect.Type); ok {
+		e.Result = typ
+	} else {
+		e.Result = reflect.TypeOf(result)
+	}
+	return e
+}
+
+// atStage sets e's Stage and StageFunc, and returns e.
+func (e *Error) atStage(stage int, fname string) *Error {
+	e.Stage, e.StageFunc = stage, fname
+	return e
+}
+
+// because sets e's Want and Reason, and returns e.
+func (e *Error) because(want signature, reason string) *Error {
+	e.Want, e.Reason = want.String(), reason
+	return e
+}
+
+// Error returns a message of the form
+//
+//	generics.Map: passed non-slice value
+//
+// or, for mismatched signatures,
+//
+//	generics.Map: expected func(int) U, got func(string) bool: parameter 0 is string, slice element is int
+//
+// Errors in a Pipeline also give the stage, as in
+//
+//	generics.Stream: stage 2 (Map): passed non-function value
+func (e *Error) Error() string {
+	prefix := packageNamePrefix + e.Func + ": "
+	if e.Stage > 0 {
+		prefix += "stage " + strconv.Itoa(e.Stage) + " (" + e.StageFunc + "): "
+	}
+	if e.Want != "" && e.Pred != nil {
+		return prefix + "expected " + e.Want + ", got " + e.Pred.String() + ": " + e.Reason
+	}
+	var msg string
+	switch e.Kind {
+	case NotSlice:
+		msg = sliceError
+	case NotFunction:
+		msg = functionError
+	case SignatureMismatch:
+		msg = typeError
+	case ZeroMismatch:
+		msg = zeroError
+	case NotOrdered:
+		msg = orderError
+	case NotType:
+		msg = resultError
+	case NotMap:
+		msg = mapError
+	case NotChan:
+		msg = chanError
+	case NegativeBuffer:
+		msg = bufferError
+	default:
+		msg = e.Kind.String()
+	}
+	return prefix + msg
+}
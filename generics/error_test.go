@@ -0,0 +1,130 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	intSliceType = reflect.TypeOf([]int(nil))
+	intType      = reflect.TypeOf(0)
+)
+
+func TestError(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+	toBool := func(b bool) bool { return b }
+	add := func(i, j int) int { return i + j }
+
+	testError(func() { Map(3, isEven) }, &Error{Func: "Map", Kind: NotSlice, Slice: intType, Pred: reflect.TypeOf(isEven)}, t)
+	testError(func() { Filter([]int{}, 3) }, &Error{Func: "Filter", Kind: NotFunction, Slice: intSliceType, Pred: intType}, t)
+	testError(func() { Reject([]int{}, nil) }, &Error{Func: "Reject", Kind: NotFunction, Slice: intSliceType}, t)
+	testError(func() { Some([]int{}, toBool) }, &Error{Func: "Some", Kind: SignatureMismatch, Slice: intSliceType, Pred: reflect.TypeOf(toBool),
+		Want: "func(int) bool", Reason: "parameter 0 is bool, slice element is int"}, t)
+	testError(func() { Foldr([]int{}, "", add) }, &Error{Func: "Foldr", Kind: ZeroMismatch, Slice: intSliceType, Pred: reflect.TypeOf(add), Zero: reflect.TypeOf(""),
+		Want: "func(int, string) string", Reason: "zero is string, parameter 1 is int"}, t)
+	testError(func() { Foldl([]int{}, nil, add) }, &Error{Func: "Foldl", Kind: ZeroMismatch, Slice: intSliceType, Pred: reflect.TypeOf(add),
+		Want: "func(U, int) U", Reason: "zero is nil, parameter 0 is int"}, t)
+	testError(func() { Max([]bool{}, nil) }, &Error{Func: "Max", Kind: NotOrdered, Slice: reflect.TypeOf([]bool(nil))}, t)
+	testError(func() { MapTo([]int{}, isEven, 3) }, &Error{Func: "MapTo", Kind: NotType, Slice: intSliceType, Pred: reflect.TypeOf(isEven), Result: intType}, t)
+	testError(func() { MapTo([]int{}, isEven, intType) }, &Error{Func: "MapTo", Kind: SignatureMismatch, Slice: intSliceType, Pred: reflect.TypeOf(isEven), Result: intType,
+		Want: "func(int) int", Reason: "result is bool, want int"}, t)
+	testError(func() { Stream([]int{}).Take(1).Filter(add).Count() }, &Error{Func: "Stream", Kind: SignatureMismatch, Slice: intSliceType, Pred: reflect.TypeOf(add),
+		Want: "func(int) bool", Reason: "has 2 parameters, want 1", Stage: 2, StageFunc: "Filter"}, t)
+}
+
+func testError(f func(), err *Error, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %#v; got %#v", err, r)
+		}
+	}()
+	f()
+}
+
+func TestErrorString(t *testing.T) {
+	testErrorString(&Error{Func: "Map", Kind: NotSlice}, mapSliceError, t)
+	testErrorString(&Error{Func: "Filter", Kind: NotFunction}, filterFunctionError, t)
+	testErrorString(&Error{Func: "Count", Kind: SignatureMismatch}, countTypeError, t)
+	testErrorString(&Error{Func: "Foldr", Kind: ZeroMismatch}, foldrZeroError, t)
+	testErrorString(&Error{Func: "Min", Kind: NotOrdered}, minOrderError, t)
+	testErrorString(&Error{Func: "MapTo", Kind: NotType}, mapToResultError, t)
+	testErrorString(&Error{Func: "Keys", Kind: NotMap}, keysMapError, t)
+	testErrorString(&Error{Func: "MapChan", Kind: NotChan}, mapChanChanError, t)
+	testErrorString(&Error{Func: "FilterChan", Kind: NegativeBuffer}, filterChanBufferError, t)
+	testErrorString(&Error{Func: "Map", Kind: SignatureMismatch, Pred: reflect.TypeOf(func(string) bool { return false }),
+		Want: "func(int) U", Reason: "parameter 0 is string, slice element is int"},
+		"generics.Map: expected func(int) U, got func(string) bool: parameter 0 is string, slice element is int", t)
+	testErrorString(&Error{Func: "Stream", Kind: NotFunction, Stage: 2, StageFunc: "Map"}, streamErrorPrefix+"stage 2 (Map): "+functionError, t)
+	testErrorString(&Error{Func: "Map", Kind: 0}, "generics.Map: ErrorKind(0)", t)
+}
+
+func testErrorString(e *Error, str string, t *testing.T) {
+	if e.Error() != str {
+		t.Errorf("Expected error string %q; got %q", str, e.Error())
+	}
+}
+
+func TestErrorVariants(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+	less := func(i, j int) bool { return i < j }
+	add := func(i, j int) int { return i + j }
+	slc := []int{1, 2, 3}
+
+	// The E variants should return the same
+	// results as their panicking counterparts
+	testErrorVariant(func() (interface{}, error) { return MapE(slc, add) }, nil, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return MapE(slc, isEven) }, []bool{false, true, false}, 0, t)
+	testErrorVariant(func() (interface{}, error) { return MapToE(slc, isEven, (*interface{})(nil)) }, []interface{}{false, true, false}, 0, t)
+	testErrorVariant(func() (interface{}, error) { return MapToE(slc, isEven, nil) }, nil, NotType, t)
+	testErrorVariant(func() (interface{}, error) { return FilterE(slc, isEven) }, []int{2}, 0, t)
+	testErrorVariant(func() (interface{}, error) { return FilterE(3, isEven) }, nil, NotSlice, t)
+	testErrorVariant(func() (interface{}, error) { return RejectE(slc, isEven) }, []int{1, 3}, 0, t)
+	testErrorVariant(func() (interface{}, error) { return RejectE(slc, 3) }, nil, NotFunction, t)
+	testErrorVariant(func() (interface{}, error) { return FoldrE(slc, 0, add) }, 6, 0, t)
+	testErrorVariant(func() (interface{}, error) { return FoldrE(slc, nil, add) }, nil, ZeroMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return FoldlE(slc, 0, add) }, 6, 0, t)
+	testErrorVariant(func() (interface{}, error) { return FoldlE(slc, 0, isEven) }, nil, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return FindE(slc, isEven) }, 2, 0, t)
+	testErrorVariant(func() (interface{}, error) { return FindE(slc, less) }, nil, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return FindIndexE(slc, isEven) }, 1, 0, t)
+	testErrorVariant(func() (interface{}, error) { return FindIndexE(slc, less) }, -1, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return SomeE(slc, isEven) }, true, 0, t)
+	testErrorVariant(func() (interface{}, error) { return SomeE(slc, less) }, false, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return EveryE(slc, isEven) }, false, 0, t)
+	testErrorVariant(func() (interface{}, error) { return EveryE(slc, less) }, false, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return CountE(slc, isEven) }, 1, 0, t)
+	testErrorVariant(func() (interface{}, error) { return CountE(slc, less) }, 0, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return MaxE(slc, less) }, 3, 0, t)
+	testErrorVariant(func() (interface{}, error) { return MaxE(slc, isEven) }, nil, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return MinE(slc, nil) }, 1, 0, t)
+	testErrorVariant(func() (interface{}, error) { return MinE([]bool{}, nil) }, nil, NotOrdered, t)
+	testErrorVariant(func() (interface{}, error) { return MapSeqE(slc, add) }, nil, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return FilterSeqE(3, isEven) }, nil, NotSlice, t)
+	testErrorVariant(func() (interface{}, error) { return RejectSeqE(slc, 3) }, nil, NotFunction, t)
+	testErrorVariant(func() (interface{}, error) { return CollectE(slc) }, slc, 0, t)
+}
+
+// testErrorVariant checks that f returns target and,
+// if kind is not 0, an *Error of that kind.
+func testErrorVariant(f func() (interface{}, error), target interface{}, kind ErrorKind, t *testing.T) {
+	ret, err := f()
+	if kind == 0 {
+		if err != nil {
+			t.Errorf("Expected no error; got %v", err)
+		}
+	} else {
+		if e, ok := err.(*Error); !ok {
+			t.Errorf("Expected *Error; got %#v", err)
+		} else if e.Kind != kind {
+			t.Errorf("Expected error kind %v; got %v", kind, e.Kind)
+		}
+	}
+	if !reflect.DeepEqual(ret, target) {
+		t.Errorf("Expected result %v; got %v", target, ret)
+	}
+}
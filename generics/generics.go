@@ -13,7 +13,72 @@
 //
 // For all functions, if the types of the arguments do not match
 // the generic argument types of the function, it will cause a
-// runtime panic.
+// runtime panic with an *Error. Each function (other than
+// Identity) has an E variant, such as MapE for Map, which
+// returns the *Error instead. Note that the E variants do
+// not recover panics raised by the functions passed to them.
+//
+// Wherever a function takes a slice (slc []T), it also accepts
+// an array ([N]T), a non-nil pointer to an array (*[N]T), a
+// string, whose elements are its runes (so T is rune), or a
+// non-nil iterator function such as an iter.Seq[T] (that is,
+// any func(yield func(T) bool)). Likewise, wherever a function
+// takes a map (m map[K]V), it also accepts an iterator function
+// over pairs, such as an iter.Seq2[K, V]. The return types for
+// each kind of input are:
+//
+//   - Map and MapTo always return a slice
+//   - Filter and Reject return a value of the same type as slc
+//     if it is a slice or a string (for strings, the runes for
+//     which pred returned true, or false, re-encoded as a
+//     string), and a []T otherwise
+//   - FindIndex returns the byte offset of the rune for
+//     strings, as a range loop would, and the number of
+//     elements before the one found otherwise
+//
+// Iterators are stopped as soon as the result is known (by
+// Find, FindIndex, Some, and Every), but Foldl must run an
+// iterator to completion before calling pred, since it visits
+// the elements in reverse. MapSeq, FilterSeq, and RejectSeq
+// are lazy forms of Map, Filter, and Reject which return
+// iterators, and Collect turns any of the above into a slice.
+//
+// To chain several of these functions without building a
+// slice at each step, use Stream, whose Pipeline applies
+// all of its stages in a single pass.
+//
+// ParallelMap, ParallelFilter, ParallelCount, ParallelSome,
+// and ParallelEvery call pred on several goroutines at once.
+//
+// The types of the arguments need not match the generic types
+// exactly; it is enough that values can be assigned wherever
+// they flow. Each element of slc must be assignable to pred's
+// parameter (so a func(fmt.Stringer) bool can filter a slice
+// of any type which implements fmt.Stringer, and a
+// func(interface{}) bool can filter any slice), and pred's
+// results must be assignable to the types they are used as.
+// The following remain errors:
+//
+//   - a parameter type to which the element type is not
+//     assignable, even if it is convertible (for example,
+//     a func(int64) bool with a []int)
+//   - a result type which is not assignable to bool where
+//     bool is expected, including named types whose
+//     underlying type is bool
+//   - the wrong number of parameters or results (a variadic
+//     function's last parameter is a slice, and is treated
+//     as such)
+//   - for Foldr and Foldl, a result type which is not
+//     assignable to the accumulator parameter (U in the
+//     generic type), or a zero which is not assignable to it
+//     (zero may be nil if the accumulator's type is an
+//     interface, pointer, slice, map, channel, or function)
+//
+// The element types of returned slices are those given by the
+// generic types, regardless of pred's parameter types; for
+// example, Filter returns a slice of the same type as slc.
+// Map's results have pred's result type; see MapTo to choose
+// another.
 //
 // Except in certain documented cases, the documented return types
 // are guaranteed to be valid. Thus, type assertions are guaranteed
@@ -26,6 +91,8 @@ package generics
 
 import (
 	"reflect"
+
+	"github.com/joshlf13/illegal"
 )
 
 // Pre-computed type literals
@@ -43,34 +110,121 @@ func Identity(x interface{}) interface{} { return x }
 // Map applies pred to each element of slc
 // successively, and returns the results.
 func Map(slc, pred interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(mapSliceError)
+	ret, err := MapE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func MapE(slc []T, pred func(T) U) ([]U, error)
+//
+// MapE is like Map, except that it returns an
+// *Error rather than panicking if the types of
+// its arguments do not match.
+func MapE(slc, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Map", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(mapFunctionError)
+		return nil, newError("Map", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
 	fType := f.Type()
 
-	// f must take a single parameter of the same type as
-	// the given slice, and return a single result
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != slcType.Elem() {
-		panic(mapTypeError)
+	// f must take a single parameter to which the
+	// elements of slc can be assigned, and return
+	// a single result
+	sig := mapSig(seq.elemParam())
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("Map", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
-	ret := reflect.MakeSlice(reflect.SliceOf(fType.Out(0)), slice.Len(), slice.Cap())
+	return mapValues(seq, f, fType.Out(0)).Interface(), nil
+}
 
-	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
-		ret.Index(i).Set(f.Call(args)[0])
+//	func MapTo(slc []T, pred func(T) U, result *V) []V
+//
+// MapTo is like Map, except that the returned
+// slice has element type V, to which each of
+// pred's results must be assignable. This
+// allows, for example, collecting results of
+// different concrete types as an interface:
+//
+//	MapTo(slc, pred, (*fmt.Stringer)(nil))
+//
+// result may either be a pointer to a value
+// of type V, as above, or a reflect.Type.
+func MapTo(slc, pred, result interface{}) interface{} {
+	ret, err := MapToE(slc, pred, result)
+	if err != nil {
+		panic(err)
 	}
+	return ret
+}
 
-	return ret.Interface()
+//	func MapToE(slc []T, pred func(T) U, result *V) ([]V, error)
+//
+// MapToE is like MapTo, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func MapToE(slc, pred, result interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("MapTo", NotSlice, slc, pred, nil).withResult(result)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("MapTo", NotFunction, slc, pred, nil).withResult(result)
+	}
+
+	resultType, ok := result.(reflect.Type)
+	if !ok {
+		resultType = reflect.TypeOf(result)
+		if resultType == nil || resultType.Kind() != reflect.Ptr {
+			return nil, newError("MapTo", NotType, slc, pred, nil).withResult(result)
+		}
+		resultType = resultType.Elem()
+	}
+
+	sig := mapToSig(seq.elemParam(), resultType)
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("MapTo", SignatureMismatch, slc, pred, nil).withResult(result).because(sig, reason)
+	}
+
+	return mapValues(seq, f, resultType).Interface(), nil
+}
+
+// mapValues implements Map and MapTo, returning
+// a slice of element type typ.
+func mapValues(seq sequence, f reflect.Value, typ reflect.Type) reflect.Value {
+	// The length of an iterator isn't known
+	// in advance, so its results are appended
+	var ret reflect.Value
+	if seq.isIter() {
+		ret = reflect.MakeSlice(reflect.SliceOf(typ), 0, 0)
+	} else {
+		ret = reflect.MakeSlice(reflect.SliceOf(typ), seq.len(), seq.cap())
+	}
+
+	j := 0
+	args := make([]reflect.Value, 1)
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
+		if seq.isIter() {
+			ret = reflect.Append(ret, f.Call(args)[0])
+		} else {
+			ret.Index(j).Set(f.Call(args)[0])
+		}
+		j++
+		return true
+	})
+
+	return ret
 }
 
 //	func Filter(slc []T, pred func(T) bool) []T
@@ -82,35 +236,46 @@ func Map(slc, pred interface{}) interface{} {
 // "true" elements, not necessarily as long
 // as slc.
 func Filter(slc, pred interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(filterSliceError)
+	ret, err := FilterE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FilterE(slc []T, pred func(T) bool) ([]T, error)
+//
+// FilterE is like Filter, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func FilterE(slc, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Filter", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(filterFunctionError)
+		return nil, newError("Filter", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.Out(0) != boolType {
-		panic(filterTypeError)
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("Filter", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
-	ret := reflect.MakeSlice(slcType, 0, 0)
+	ret := seq.subset()
 
 	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
 		if f.Call(args)[0].Bool() {
-			ret = reflect.Append(ret, args[0])
+			ret = reflect.Append(ret, v)
 		}
-	}
+		return true
+	})
 
-	return ret.Interface()
+	return seq.result(ret), nil
 }
 
 //	func Reject(slc []T, pred func(T) bool) []T
@@ -122,35 +287,46 @@ func Filter(slc, pred interface{}) interface{} {
 // "false" elements, not necessarily as long
 // as slc.
 func Reject(slc, pred interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(rejectSliceError)
+	ret, err := RejectE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func RejectE(slc []T, pred func(T) bool) ([]T, error)
+//
+// RejectE is like Reject, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func RejectE(slc, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Reject", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(rejectFunctionError)
+		return nil, newError("Reject", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.Out(0) != boolType {
-		panic(rejectTypeError)
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("Reject", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
-	ret := reflect.MakeSlice(slcType, 0, 0)
+	ret := seq.subset()
 
 	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
 		if !f.Call(args)[0].Bool() {
-			ret = reflect.Append(ret, args[0])
+			ret = reflect.Append(ret, v)
 		}
-	}
+		return true
+	})
 
-	return ret.Interface()
+	return seq.result(ret), nil
 }
 
 //	func foldl(slc []T, zero U, pred func(T, U) U) U
@@ -165,41 +341,56 @@ func Reject(slc, pred interface{}) interface{} {
 //	...
 //	return tmp
 func Foldr(slc, zero, pred interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(foldrSliceError)
+	ret, err := FoldrE(slc, zero, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FoldrE(slc []T, zero U, pred func(T, U) U) (U, error)
+//
+// FoldrE is like Foldr, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func FoldrE(slc, zero, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Foldr", NotSlice, slc, pred, zero)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(foldrFunctionError)
+		return nil, newError("Foldr", NotFunction, slc, pred, zero)
 	}
 
-	z := reflect.ValueOf(zero)
-
-	slcType := slice.Type()
-	elemType := slcType.Elem()
 	fType := f.Type()
 
-	if fType.NumIn() != 2 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.In(1) != fType.Out(0) {
-		panic(foldrTypeError)
+	sig := foldrSig(seq.elemParam(), typeParam("U"))
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("Foldr", SignatureMismatch, slc, pred, zero).because(sig, reason)
 	}
 
 	// It's possible to have a valid function
 	// (that is, func(A, B)B) and have the type
-	// of zero not be equal to B
-	if fType.Out(0) != z.Type() {
-		panic(foldrZeroError)
+	// of zero not be assignable to B
+	z, reason := zeroValue(reflect.ValueOf(zero), fType.In(1), "parameter 1")
+	if reason != "" {
+		if z.IsValid() {
+			sig = foldrSig(seq.elemParam(), concrete(z.Type(), "zero"))
+		}
+		return nil, newError("Foldr", ZeroMismatch, slc, pred, zero).because(sig, reason)
 	}
 
 	args := make([]reflect.Value, 2)
 	args[1] = z
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
 		args[1] = f.Call(args)[0]
-	}
+		return true
+	})
 
-	return args[1].Interface()
+	return args[1].Interface(), nil
 }
 
 //	func Foldl(slc []T, zero U, pred func(U, T) U) U
@@ -214,41 +405,56 @@ func Foldr(slc, zero, pred interface{}) interface{} {
 //	...
 //	return tmp
 func Foldl(slc, zero, pred interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(foldlSliceError)
+	ret, err := FoldlE(slc, zero, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FoldlE(slc []T, zero U, pred func(U, T) U) (U, error)
+//
+// FoldlE is like Foldl, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func FoldlE(slc, zero, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Foldl", NotSlice, slc, pred, zero)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(foldlFunctionError)
+		return nil, newError("Foldl", NotFunction, slc, pred, zero)
 	}
 
-	z := reflect.ValueOf(zero)
-
-	slcType := slice.Type()
-	elemType := slcType.Elem()
 	fType := f.Type()
 
-	if fType.NumIn() != 2 || fType.NumOut() != 1 || fType.In(1) != elemType || fType.In(0) != fType.Out(0) {
-		panic(foldlTypeError)
+	sig := foldlSig(seq.elemParam(), typeParam("U"))
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("Foldl", SignatureMismatch, slc, pred, zero).because(sig, reason)
 	}
 
 	// It's possible to have a valid function
 	// (that is, func(B, A)B) and have the type
-	// of zero not be equal to B
-	if fType.Out(0) != z.Type() {
-		panic(foldlZeroError)
+	// of zero not be assignable to B
+	z, reason := zeroValue(reflect.ValueOf(zero), fType.In(0), "parameter 0")
+	if reason != "" {
+		if z.IsValid() {
+			sig = foldlSig(seq.elemParam(), concrete(z.Type(), "zero"))
+		}
+		return nil, newError("Foldl", ZeroMismatch, slc, pred, zero).because(sig, reason)
 	}
 
 	args := make([]reflect.Value, 2)
 	args[0] = z
-	for i := slice.Len() - 1; i > -1; i-- {
-		args[1] = slice.Index(i)
+	seq.reverse(func(i int, v reflect.Value) bool {
+		args[1] = v
 		args[0] = f.Call(args)[0]
-	}
+		return true
+	})
 
-	return args[0].Interface()
+	return args[0].Interface(), nil
 }
 
 //	func Find(slc []T, pred func(T) bool) T
@@ -265,33 +471,46 @@ func Foldl(slc, zero, pred interface{}) interface{} {
 // may fail, which breaks the contract which
 // most other functions in this package obey.
 func Find(slc, pred interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(findSliceError)
+	ret, err := FindE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FindE(slc []T, pred func(T) bool) (T, error)
+//
+// FindE is like Find, except that it returns an
+// *Error rather than panicking if the types of
+// its arguments do not match.
+func FindE(slc, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Find", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(findFunctionError)
+		return nil, newError("Find", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.Out(0) != boolType {
-		panic(findTypeError)
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("Find", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
+	var ret interface{}
 	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
 		if f.Call(args)[0].Bool() {
-			return args[0].Interface()
+			ret = v.Interface()
+			return false
 		}
-	}
+		return true
+	})
 
-	return nil
+	return ret, nil
 }
 
 //	func FindIndex(slc []T, pred func(T) bool) int
@@ -301,33 +520,46 @@ func Find(slc, pred interface{}) interface{} {
 // pred returns true. If pred never returns true,
 // FindIndex returns -1.
 func FindIndex(slc, pred interface{}) int {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(findIndexSliceError)
+	ret, err := FindIndexE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FindIndexE(slc []T, pred func(T) bool) (int, error)
+//
+// FindIndexE is like FindIndex, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func FindIndexE(slc, pred interface{}) (int, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return -1, newError("FindIndex", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(findIndexFunctionError)
+		return -1, newError("FindIndex", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.Out(0) != boolType {
-		panic(findIndexTypeError)
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return -1, newError("FindIndex", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
+	ret := -1
 	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
 		if f.Call(args)[0].Bool() {
-			return i
+			ret = i
+			return false
 		}
-	}
+		return true
+	})
 
-	return -1
+	return ret, nil
 }
 
 //	func Some(slc []T, pred func(T) bool) bool
@@ -336,33 +568,43 @@ func FindIndex(slc, pred interface{}) int {
 // If any of those calls returns true, Contains
 // returns true. Otherwise, it returns false.
 func Some(slc, pred interface{}) bool {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(someSliceError)
+	ret, err := SomeE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func SomeE(slc []T, pred func(T) bool) (bool, error)
+//
+// SomeE is like Some, except that it returns an
+// *Error rather than panicking if the types of
+// its arguments do not match.
+func SomeE(slc, pred interface{}) (bool, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return false, newError("Some", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(someFunctionError)
+		return false, newError("Some", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.Out(0) != boolType {
-		panic(someTypeError)
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return false, newError("Some", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
+	ret := false
 	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
-		if f.Call(args)[0].Bool() {
-			return true
-		}
-	}
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
+		ret = f.Call(args)[0].Bool()
+		return !ret
+	})
 
-	return false
+	return ret, nil
 }
 
 //	func Every(slc []T, pred func(T) bool) bool
@@ -371,33 +613,43 @@ func Some(slc, pred interface{}) bool {
 // If any of those calls returns false, Every
 // returns false. Otherwise, it returns true.
 func Every(slc, pred interface{}) bool {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(everySliceError)
+	ret, err := EveryE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func EveryE(slc []T, pred func(T) bool) (bool, error)
+//
+// EveryE is like Every, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func EveryE(slc, pred interface{}) (bool, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return false, newError("Every", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(everyFunctionError)
+		return false, newError("Every", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.Out(0) != boolType {
-		panic(everyTypeError)
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return false, newError("Every", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
+	ret := true
 	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
-		if !f.Call(args)[0].Bool() {
-			return false
-		}
-	}
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
+		ret = f.Call(args)[0].Bool()
+		return ret
+	})
 
-	return true
+	return ret, nil
 }
 
 //	func Count(slc []T, pred func(T) bool) int
@@ -406,34 +658,45 @@ func Every(slc, pred interface{}) bool {
 // and returns the number of elements for which
 // the call returned true.
 func Count(slc, pred interface{}) int {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(countSliceError)
+	ret, err := CountE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func CountE(slc []T, pred func(T) bool) (int, error)
+//
+// CountE is like Count, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func CountE(slc, pred interface{}) (int, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return 0, newError("Count", NotSlice, slc, pred, nil)
 	}
 
 	f := reflect.ValueOf(pred)
 	if f.Kind() != reflect.Func {
-		panic(countFunctionError)
+		return 0, newError("Count", NotFunction, slc, pred, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.Out(0) != boolType {
-		panic(countTypeError)
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return 0, newError("Count", SignatureMismatch, slc, pred, nil).because(sig, reason)
 	}
 
 	ret := 0
 	args := make([]reflect.Value, 1)
-	for i := 0; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		args[0] = v
 		if f.Call(args)[0].Bool() {
 			ret++
 		}
-	}
+		return true
+	})
 
-	return ret
+	return ret, nil
 }
 
 //	func Max(slc []T, less func(T, T) bool) T
@@ -444,39 +707,62 @@ func Count(slc, pred interface{}) int {
 // thus breaking the type assertion guarantee.
 // However, so long as len(slc) > 0, the type
 // assertion guarantee holds.
+//
+// If less is nil, Max uses the natural order
+// defined by illegal.Compare, and panics if the
+// element type is not ordered.
 func Max(slc, less interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(maxSliceError)
+	ret, err := MaxE(slc, less)
+	if err != nil {
+		panic(err)
 	}
+	return ret
+}
 
-	f := reflect.ValueOf(less)
-	if f.Kind() != reflect.Func {
-		panic(maxFunctionError)
+//	func MaxE(slc []T, less func(T, T) bool) (T, error)
+//
+// MaxE is like Max, except that it returns an
+// *Error rather than panicking if the types of
+// its arguments do not match.
+func MaxE(slc, less interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Max", NotSlice, slc, less, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 2 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.In(1) != elemType || fType.Out(0) != boolType {
-		panic(maxTypeError)
+	f := reflect.ValueOf(less)
+	if less == nil {
+		if !illegal.Ordered(seq.elem) {
+			return nil, newError("Max", NotOrdered, slc, less, nil)
+		}
+		f = naturalLess(seq.elem)
+	}
+	if f.Kind() != reflect.Func {
+		return nil, newError("Max", NotFunction, slc, less, nil)
 	}
 
-	if slice.Len() == 0 {
-		return nil
+	sig := lessSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("Max", SignatureMismatch, slc, less, nil).because(sig, reason)
 	}
 
 	args := make([]reflect.Value, 2)
-	args[0] = slice.Index(0)
-	for i := 1; i < slice.Len(); i++ {
-		args[1] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		if !args[0].IsValid() {
+			args[0] = v
+			return true
+		}
+		args[1] = v
 		if f.Call(args)[0].Bool() {
 			args[0] = args[1]
 		}
-	}
+		return true
+	})
 
-	return args[0].Interface()
+	if !args[0].IsValid() {
+		return nil, nil
+	}
+	return args[0].Interface(), nil
 }
 
 //	func Min(slc []T, less func(T, T) bool) T
@@ -487,39 +773,95 @@ func Max(slc, less interface{}) interface{} {
 // thus breaking the type assertion guarantee.
 // However, so long as len(slc) > 0, the type
 // assertion guarantee holds.
+//
+// If less is nil, Min uses the natural order
+// defined by illegal.Compare, and panics if the
+// element type is not ordered.
 func Min(slc, less interface{}) interface{} {
-	slice := reflect.ValueOf(slc)
-	if slice.Kind() != reflect.Slice {
-		panic(minSliceError)
+	ret, err := MinE(slc, less)
+	if err != nil {
+		panic(err)
 	}
+	return ret
+}
 
-	f := reflect.ValueOf(less)
-	if f.Kind() != reflect.Func {
-		panic(minFunctionError)
+//	func MinE(slc []T, less func(T, T) bool) (T, error)
+//
+// MinE is like Min, except that it returns an
+// *Error rather than panicking if the types of
+// its arguments do not match.
+func MinE(slc, less interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Min", NotSlice, slc, less, nil)
 	}
 
-	slcType := slice.Type()
-	elemType := slcType.Elem()
-	fType := f.Type()
-
-	if fType.NumIn() != 2 || fType.NumOut() != 1 || fType.In(0) != elemType || fType.In(1) != elemType || fType.Out(0) != boolType {
-		panic(minTypeError)
+	f := reflect.ValueOf(less)
+	if less == nil {
+		if !illegal.Ordered(seq.elem) {
+			return nil, newError("Min", NotOrdered, slc, less, nil)
+		}
+		f = naturalLess(seq.elem)
+	}
+	if f.Kind() != reflect.Func {
+		return nil, newError("Min", NotFunction, slc, less, nil)
 	}
 
-	if slice.Len() == 0 {
-		return nil
+	sig := lessSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("Min", SignatureMismatch, slc, less, nil).because(sig, reason)
 	}
 
 	args := make([]reflect.Value, 2)
-	args[1] = slice.Index(0)
-	for i := 1; i < slice.Len(); i++ {
-		args[0] = slice.Index(i)
+	seq.each(func(i int, v reflect.Value) bool {
+		if !args[1].IsValid() {
+			args[1] = v
+			return true
+		}
+		args[0] = v
 		if f.Call(args)[0].Bool() {
 			args[1] = args[0]
 		}
+		return true
+	})
+
+	if !args[1].IsValid() {
+		return nil, nil
+	}
+	return args[1].Interface(), nil
+}
+
+// zeroValue converts zero, which will be passed
+// to a parameter of type typ, to that type. If
+// zero is nil (the invalid Value), it is taken
+// to be the zero value of typ, if that is nil.
+// If zero cannot be converted, zeroValue returns
+// the reason, in which where describes typ.
+func zeroValue(zero reflect.Value, typ reflect.Type, where string) (reflect.Value, string) {
+	if !zero.IsValid() {
+		switch typ.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(typ), ""
+		}
+		return zero, "zero is nil, " + where + " is " + typ.String()
+	}
+	if !zero.Type().AssignableTo(typ) {
+		return zero, "zero is " + zero.Type().String() + ", " + where + " is " + typ.String()
 	}
+	v := reflect.New(typ).Elem()
+	v.Set(zero)
+	return v, ""
+}
 
-	return args[1].Interface()
+// naturalLess returns a func(T, T) bool, where
+// T is typ, which orders its arguments as
+// illegal.Compare does. typ must be ordered.
+func naturalLess(typ reflect.Type) reflect.Value {
+	compare := illegal.CompareFunc(typ)
+	fType := reflect.FuncOf([]reflect.Type{typ, typ}, []reflect.Type{boolType}, false)
+	return reflect.MakeFunc(fType, func(args []reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.ValueOf(compare(args[0], args[1]) < 0)}
+	})
 }
 
 var (
@@ -541,6 +883,11 @@ var (
 	functionError     = "passed non-function value"
 	typeError         = "function type and slice type do not match"
 	zeroError         = "zero type and function return type do not match"
+	resultError       = "passed invalid result type"
+	mapError          = "passed non-map value"
+	chanError         = "passed non-channel or send-only channel value"
+	bufferError       = "passed negative buffer size"
+	orderError        = "element type is not ordered"
 	packageNamePrefix = "generics."
 
 	mapErrorPrefix   = packageNamePrefix + "Map: "
@@ -548,6 +895,11 @@ var (
 	mapFunctionError = mapErrorPrefix + functionError
 	mapTypeError     = mapErrorPrefix + typeError
 
+	mapToErrorPrefix   = packageNamePrefix + "MapTo: "
+	mapToSliceError    = mapToErrorPrefix + sliceError
+	mapToFunctionError = mapToErrorPrefix + functionError
+	mapToResultError   = mapToErrorPrefix + resultError
+
 	filterErrorPrefix   = packageNamePrefix + "Filter: "
 	filterSliceError    = filterErrorPrefix + sliceError
 	filterFunctionError = filterErrorPrefix + functionError
@@ -599,9 +951,83 @@ var (
 	maxSliceError    = maxErrorPrefix + sliceError
 	maxFunctionError = maxErrorPrefix + functionError
 	maxTypeError     = maxErrorPrefix + typeError
+	maxOrderError    = maxErrorPrefix + orderError
 
 	minErrorPrefix   = packageNamePrefix + "Min: "
 	minSliceError    = minErrorPrefix + sliceError
 	minFunctionError = minErrorPrefix + functionError
 	minTypeError     = minErrorPrefix + typeError
+	minOrderError    = minErrorPrefix + orderError
+
+	mapValuesErrorPrefix   = packageNamePrefix + "MapValues: "
+	mapValuesMapError      = mapValuesErrorPrefix + mapError
+	mapValuesFunctionError = mapValuesErrorPrefix + functionError
+
+	mapEntriesErrorPrefix   = packageNamePrefix + "MapEntries: "
+	mapEntriesMapError      = mapEntriesErrorPrefix + mapError
+	mapEntriesFunctionError = mapEntriesErrorPrefix + functionError
+
+	filterMapErrorPrefix   = packageNamePrefix + "FilterMap: "
+	filterMapMapError      = filterMapErrorPrefix + mapError
+	filterMapFunctionError = filterMapErrorPrefix + functionError
+
+	keysErrorPrefix   = packageNamePrefix + "Keys: "
+	keysMapError      = keysErrorPrefix + mapError
+	keysFunctionError = keysErrorPrefix + functionError
+
+	valuesErrorPrefix   = packageNamePrefix + "Values: "
+	valuesMapError      = valuesErrorPrefix + mapError
+	valuesFunctionError = valuesErrorPrefix + functionError
+
+	foldMapErrorPrefix   = packageNamePrefix + "FoldMap: "
+	foldMapMapError      = foldMapErrorPrefix + mapError
+	foldMapFunctionError = foldMapErrorPrefix + functionError
+
+	mapChanErrorPrefix   = packageNamePrefix + "MapChan: "
+	mapChanChanError     = mapChanErrorPrefix + chanError
+	mapChanFunctionError = mapChanErrorPrefix + functionError
+	mapChanBufferError   = mapChanErrorPrefix + bufferError
+
+	filterChanErrorPrefix   = packageNamePrefix + "FilterChan: "
+	filterChanChanError     = filterChanErrorPrefix + chanError
+	filterChanFunctionError = filterChanErrorPrefix + functionError
+	filterChanBufferError   = filterChanErrorPrefix + bufferError
+
+	mapSeqErrorPrefix   = packageNamePrefix + "MapSeq: "
+	mapSeqSliceError    = mapSeqErrorPrefix + sliceError
+	mapSeqFunctionError = mapSeqErrorPrefix + functionError
+
+	filterSeqErrorPrefix   = packageNamePrefix + "FilterSeq: "
+	filterSeqSliceError    = filterSeqErrorPrefix + sliceError
+	filterSeqFunctionError = filterSeqErrorPrefix + functionError
+
+	rejectSeqErrorPrefix   = packageNamePrefix + "RejectSeq: "
+	rejectSeqSliceError    = rejectSeqErrorPrefix + sliceError
+	rejectSeqFunctionError = rejectSeqErrorPrefix + functionError
+
+	collectErrorPrefix = packageNamePrefix + "Collect: "
+	collectSliceError  = collectErrorPrefix + sliceError
+
+	streamErrorPrefix = packageNamePrefix + "Stream: "
+	streamSliceError  = streamErrorPrefix + sliceError
+
+	parallelMapErrorPrefix   = packageNamePrefix + "ParallelMap: "
+	parallelMapSliceError    = parallelMapErrorPrefix + sliceError
+	parallelMapFunctionError = parallelMapErrorPrefix + functionError
+
+	parallelFilterErrorPrefix   = packageNamePrefix + "ParallelFilter: "
+	parallelFilterSliceError    = parallelFilterErrorPrefix + sliceError
+	parallelFilterFunctionError = parallelFilterErrorPrefix + functionError
+
+	parallelCountErrorPrefix   = packageNamePrefix + "ParallelCount: "
+	parallelCountSliceError    = parallelCountErrorPrefix + sliceError
+	parallelCountFunctionError = parallelCountErrorPrefix + functionError
+
+	parallelSomeErrorPrefix   = packageNamePrefix + "ParallelSome: "
+	parallelSomeSliceError    = parallelSomeErrorPrefix + sliceError
+	parallelSomeFunctionError = parallelSomeErrorPrefix + functionError
+
+	parallelEveryErrorPrefix   = packageNamePrefix + "ParallelEvery: "
+	parallelEverySliceError    = parallelEveryErrorPrefix + sliceError
+	parallelEveryFunctionError = parallelEveryErrorPrefix + functionError
 )
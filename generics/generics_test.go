@@ -31,18 +31,20 @@ func TestMap(t *testing.T) {
 	testMap([]int{1, 2, 3}, []int{1, 4, 9}, func(i int) int { return i * i }, nil, t)
 	testMap([]int{}, []int{}, func(i int) int { return 3 }, nil, t)
 	testMap([]int{1, 2, 3}, []bool{false, false, false}, func(i int) bool { return false }, nil, t)
+	testMap([]int{1, 2}, []string{"1", "2"}, func(i interface{}) string { return fmt.Sprint(i) }, nil, t)
 
 	// Map should panic
-	testMap([]int{}, nil, func(b bool) int { return 3 }, mapTypeError, t)
+	testMap([]int{}, nil, func(b bool) int { return 3 }, mapErrorPrefix+"expected func(int) U, got func(bool) int: parameter 0 is bool, slice element is int", t)
 	testMap([]int{}, nil, 3, mapFunctionError, t)
 	testMap(3, nil, func() {}, mapSliceError, t)
-	testMap([]int{}, nil, func(i, j int) int { return i * j }, mapTypeError, t)
-	testMap([]int{}, nil, func(i int) (int, int) { return i, i }, mapTypeError, t)
+	testMap([]int{}, nil, func(i, j int) int { return i * j }, mapErrorPrefix+"expected func(int) U, got func(int, int) int: has 2 parameters, want 1", t)
+	testMap([]int{}, nil, func(i int) (int, int) { return i, i }, mapErrorPrefix+"expected func(int) U, got func(int) (int, int): has 2 results, want 1", t)
+	testMap([]int{}, nil, func(i int64) int64 { return i }, mapErrorPrefix+"expected func(int) U, got func(int64) int64: parameter 0 is int64, slice element is int", t)
 }
 
 func testMap(slc1, slc2, f interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -55,24 +57,69 @@ func testMap(slc1, slc2, f interface{}, err interface{}, t *testing.T) {
 	}
 }
 
+func TestMapTo(t *testing.T) {
+	itoa := func(i int) testStringer { return testStringer(i) }
+	stringerType := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+	// MapTo should succeed
+	testMapTo([]int{1, 2}, itoa, (*fmt.Stringer)(nil), []fmt.Stringer{testStringer(1), testStringer(2)}, nil, t)
+	testMapTo([]int{1, 2}, itoa, stringerType, []fmt.Stringer{testStringer(1), testStringer(2)}, nil, t)
+	testMapTo([]int{1, 2}, itoa, (*testStringer)(nil), []testStringer{1, 2}, nil, t)
+	testMapTo([]int{}, itoa, (*interface{})(nil), []interface{}{}, nil, t)
+
+	// MapTo should panic
+	testMapTo(3, itoa, (*fmt.Stringer)(nil), nil, mapToSliceError, t)
+	testMapTo([]int{}, 3, (*fmt.Stringer)(nil), nil, mapToFunctionError, t)
+	testMapTo([]int{}, itoa, nil, nil, mapToResultError, t)
+	testMapTo([]int{}, itoa, testStringer(0), nil, mapToResultError, t)
+	testMapTo([]int{}, itoa, (*int)(nil), nil, mapToErrorPrefix+"expected func(int) int, got func(int) generics.testStringer: result is generics.testStringer, want int", t)
+	testMapTo([]int{}, func(i int) int { return i }, (*fmt.Stringer)(nil), nil,
+		mapToErrorPrefix+"expected func(int) fmt.Stringer, got func(int) int: result is int, want fmt.Stringer", t)
+}
+
+func testMapTo(slc1, f, result, slc2 interface{}, err interface{}, t *testing.T) {
+	defer func() {
+		r := errorString(recover())
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	slc3 := MapTo(slc1, f, result)
+
+	if !reflect.DeepEqual(slc2, slc3) {
+		t.Errorf("Expected result %v; got %v", slc2, slc3)
+	}
+}
+
+type testStringer int
+
+func (s testStringer) String() string { return fmt.Sprint(int(s)) }
+
+type testBool bool
+
 func TestFilter(t *testing.T) {
 	// Filter should succeed
 	testFilter([]int{1, 2, 3, 4}, []int{2, 4}, func(i int) bool { return i%2 == 0 }, nil, t)
 	testFilter([]int{1, 2, 3, 4}, []int{}, func(i int) bool { return false }, nil, t)
 	testFilter([]int{1, 2, 3, 4}, []int{1, 2, 3, 4}, func(i int) bool { return true }, nil, t)
+	testFilter([]testStringer{1, 22}, []testStringer{22}, func(s fmt.Stringer) bool { return len(s.String()) > 1 }, nil, t)
+	testFilter([]int{1, 2}, []int{2}, func(i interface{}) bool { return i == 2 }, nil, t)
 
 	// Filter should panic
-	testFilter([]int{1, 2, 3, 4}, nil, func(b bool) bool { return false }, filterTypeError, t)
-	testFilter([]int{1, 2, 3, 4}, nil, func(i int) int { return i }, filterTypeError, t)
+	testFilter([]int{1, 2, 3, 4}, nil, func(b bool) bool { return false }, filterErrorPrefix+"expected func(int) bool, got func(bool) bool: parameter 0 is bool, slice element is int", t)
+	testFilter([]int{1, 2, 3, 4}, nil, func(i int) int { return i }, filterErrorPrefix+"expected func(int) bool, got func(int) int: result is int, want bool", t)
 	testFilter([]int{}, nil, 3, filterFunctionError, t)
-	testFilter([]int{}, nil, func(i, j int) bool { return false }, filterTypeError, t)
-	testFilter([]int{}, nil, func(i, j int) (bool, bool) { return false, false }, filterTypeError, t)
+	testFilter([]int{}, nil, func(i, j int) bool { return false }, filterErrorPrefix+"expected func(int) bool, got func(int, int) bool: has 2 parameters, want 1", t)
+	testFilter([]int{}, nil, func(i, j int) (bool, bool) { return false, false }, filterErrorPrefix+"expected func(int) bool, got func(int, int) (bool, bool): has 2 parameters, want 1; has 2 results, want 1", t)
 	testFilter(3, nil, func() {}, filterSliceError, t)
+	testFilter([]int{}, nil, func(i int) testBool { return false }, filterErrorPrefix+"expected func(int) bool, got func(int) generics.testBool: result is generics.testBool, want bool", t)
+	testFilter([]int{}, nil, func(s fmt.Stringer) bool { return false }, filterErrorPrefix+"expected func(int) bool, got func(fmt.Stringer) bool: parameter 0 is fmt.Stringer, slice element is int", t)
 }
 
 func testFilter(slc1, slc2, f interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -92,17 +139,17 @@ func TestReject(t *testing.T) {
 	testReject([]int{1, 2, 3, 4}, []int{}, func(i int) bool { return true }, nil, t)
 
 	// Reject should panic
-	testReject([]int{1, 2, 3, 4}, nil, func(b bool) bool { return false }, rejectTypeError, t)
-	testReject([]int{1, 2, 3, 4}, nil, func(i int) int { return i }, rejectTypeError, t)
+	testReject([]int{1, 2, 3, 4}, nil, func(b bool) bool { return false }, rejectErrorPrefix+"expected func(int) bool, got func(bool) bool: parameter 0 is bool, slice element is int", t)
+	testReject([]int{1, 2, 3, 4}, nil, func(i int) int { return i }, rejectErrorPrefix+"expected func(int) bool, got func(int) int: result is int, want bool", t)
 	testReject([]int{}, nil, 3, rejectFunctionError, t)
-	testReject([]int{}, nil, func(i, j int) bool { return false }, rejectTypeError, t)
-	testReject([]int{}, nil, func(i, j int) (bool, bool) { return false, false }, rejectTypeError, t)
+	testReject([]int{}, nil, func(i, j int) bool { return false }, rejectErrorPrefix+"expected func(int) bool, got func(int, int) bool: has 2 parameters, want 1", t)
+	testReject([]int{}, nil, func(i, j int) (bool, bool) { return false, false }, rejectErrorPrefix+"expected func(int) bool, got func(int, int) (bool, bool): has 2 parameters, want 1; has 2 results, want 1", t)
 	testReject(3, nil, func() {}, rejectSliceError, t)
 }
 
 func testReject(slc1, slc2, f interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -121,20 +168,26 @@ func TestFoldr(t *testing.T) {
 	testFoldr([]int{1, 2, 3}, 6, func(i, j int) int { return j - i }, 0, nil, t)
 	testFoldr([]int{1, 2, 3}, "", func(i int, s string) string { return fmt.Sprintf("%d%s", i, s) }, "321", nil, t)
 	testFoldr([]int{}, "", func(i int, s string) string { return "foo" }, "", nil, t)
+	testFoldr([]int{1, 2}, nil, func(i int, acc []int) []int { return append(acc, i) }, []int{1, 2}, nil, t)
+	testFoldr([]int{1, 2}, 0, func(i int, acc interface{}) interface{} { return acc.(int) + i }, 3, nil, t)
+	testFoldr([]int{1, 2}, 0, func(i interface{}, acc interface{}) int { return acc.(int) + i.(int) }, 3, nil, t)
 
 	// Foldr should fail
 	testFoldr(3, 0, 0, nil, foldrSliceError, t)
+	testFoldr([]int{}, 0, func(i int, s fmt.Stringer) interface{} { return s }, nil,
+		foldrErrorPrefix+"expected func(int, U) U, got func(int, fmt.Stringer) interface {}: result is interface {}, parameter 1 is fmt.Stringer", t)
+	testFoldr([]int{}, nil, func(i, j int) int { return 0 }, nil, foldrErrorPrefix+"expected func(int, U) U, got func(int, int) int: zero is nil, parameter 1 is int", t)
 	testFoldr([]int{}, 0, 0, nil, foldrFunctionError, t)
-	testFoldr([]int{}, 0, func(i, j, k int) int { return 0 }, nil, foldrTypeError, t)
-	testFoldr([]int{}, 0, func(i, j int) (int, int) { return 0, 0 }, nil, foldrTypeError, t)
-	testFoldr([]int{}, false, func(i, j int) bool { return false }, nil, foldrTypeError, t)
-	testFoldr([]int{}, 0, func(i int, b bool) bool { return false }, nil, foldrZeroError, t)
-	testFoldr([]int{}, 0, func(i, j int) bool { return false }, nil, foldrTypeError, t)
+	testFoldr([]int{}, 0, func(i, j, k int) int { return 0 }, nil, foldrErrorPrefix+"expected func(int, U) U, got func(int, int, int) int: has 3 parameters, want 2", t)
+	testFoldr([]int{}, 0, func(i, j int) (int, int) { return 0, 0 }, nil, foldrErrorPrefix+"expected func(int, U) U, got func(int, int) (int, int): has 2 results, want 1", t)
+	testFoldr([]int{}, false, func(i, j int) bool { return false }, nil, foldrErrorPrefix+"expected func(int, U) U, got func(int, int) bool: result is bool, parameter 1 is int", t)
+	testFoldr([]int{}, 0, func(i int, b bool) bool { return false }, nil, foldrErrorPrefix+"expected func(int, int) int, got func(int, bool) bool: zero is int, parameter 1 is bool", t)
+	testFoldr([]int{}, 0, func(i, j int) bool { return false }, nil, foldrErrorPrefix+"expected func(int, U) U, got func(int, int) bool: result is bool, parameter 1 is int", t)
 }
 
 func testFoldr(slc, z, f interface{}, res interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -153,20 +206,21 @@ func TestFoldl(t *testing.T) {
 	testFoldl([]int{3, 2, 1}, 6, func(i, j int) int { return i - j }, 0, nil, t)
 	testFoldl([]int{1, 2, 3}, "", func(s string, i int) string { return fmt.Sprintf("%d%s", i, s) }, "123", nil, t)
 	testFoldl([]int{}, "", func(s string, i int) string { return "foo" }, "", nil, t)
+	testFoldl([]int{1, 2}, nil, func(acc []int, i int) []int { return append(acc, i) }, []int{2, 1}, nil, t)
 
 	// Foldl should fail
 	testFoldl(3, 0, 0, nil, foldlSliceError, t)
 	testFoldl([]int{}, 0, 0, nil, foldlFunctionError, t)
-	testFoldl([]int{}, 0, func(i, j, k int) int { return 0 }, nil, foldlTypeError, t)
-	testFoldl([]int{}, 0, func(i, j int) (int, int) { return 0, 0 }, nil, foldlTypeError, t)
-	testFoldl([]int{}, false, func(i, j int) bool { return false }, nil, foldlTypeError, t)
-	testFoldl([]int{}, 0, func(b bool, i int) bool { return false }, nil, foldlZeroError, t)
-	testFoldl([]int{}, 0, func(i, j int) bool { return false }, nil, foldlTypeError, t)
+	testFoldl([]int{}, 0, func(i, j, k int) int { return 0 }, nil, foldlErrorPrefix+"expected func(U, int) U, got func(int, int, int) int: has 3 parameters, want 2", t)
+	testFoldl([]int{}, 0, func(i, j int) (int, int) { return 0, 0 }, nil, foldlErrorPrefix+"expected func(U, int) U, got func(int, int) (int, int): has 2 results, want 1", t)
+	testFoldl([]int{}, false, func(i, j int) bool { return false }, nil, foldlErrorPrefix+"expected func(U, int) U, got func(int, int) bool: result is bool, parameter 0 is int", t)
+	testFoldl([]int{}, 0, func(b bool, i int) bool { return false }, nil, foldlErrorPrefix+"expected func(int, int) int, got func(bool, int) bool: zero is int, parameter 0 is bool", t)
+	testFoldl([]int{}, 0, func(i, j int) bool { return false }, nil, foldlErrorPrefix+"expected func(U, int) U, got func(int, int) bool: result is bool, parameter 0 is int", t)
 }
 
 func testFoldl(slc, z, f interface{}, res interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -189,14 +243,14 @@ func TestFind(t *testing.T) {
 	// Find should fail
 	testFind(3, nil, nil, findSliceError, t)
 	testFind([]int{1, 2, 3}, 3, nil, findFunctionError, t)
-	testFind([]int{1, 2, 3}, func(b bool) bool { return b }, nil, findTypeError, t)
-	testFind([]int{1, 2, 3}, func(i int) int { return i }, nil, findTypeError, t)
-	testFind([]int{1, 2, 3}, func(i, j int) bool { return i == j }, nil, findTypeError, t)
+	testFind([]int{1, 2, 3}, func(b bool) bool { return b }, nil, findErrorPrefix+"expected func(int) bool, got func(bool) bool: parameter 0 is bool, slice element is int", t)
+	testFind([]int{1, 2, 3}, func(i int) int { return i }, nil, findErrorPrefix+"expected func(int) bool, got func(int) int: result is int, want bool", t)
+	testFind([]int{1, 2, 3}, func(i, j int) bool { return i == j }, nil, findErrorPrefix+"expected func(int) bool, got func(int, int) bool: has 2 parameters, want 1", t)
 }
 
 func testFind(slc, pred, target interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -218,14 +272,14 @@ func TestFindIndex(t *testing.T) {
 	// FindIndex should fail
 	testFindIndex(3, nil, -1, findIndexSliceError, t)
 	testFindIndex([]int{1, 2, 3}, 3, -1, findIndexFunctionError, t)
-	testFindIndex([]int{1, 2, 3}, func(b bool) bool { return b }, -1, findIndexTypeError, t)
-	testFindIndex([]int{1, 2, 3}, func(i int) int { return i }, -1, findIndexTypeError, t)
-	testFindIndex([]int{1, 2, 3}, func(i, j int) bool { return i == j }, -1, findIndexTypeError, t)
+	testFindIndex([]int{1, 2, 3}, func(b bool) bool { return b }, -1, findIndexErrorPrefix+"expected func(int) bool, got func(bool) bool: parameter 0 is bool, slice element is int", t)
+	testFindIndex([]int{1, 2, 3}, func(i int) int { return i }, -1, findIndexErrorPrefix+"expected func(int) bool, got func(int) int: result is int, want bool", t)
+	testFindIndex([]int{1, 2, 3}, func(i, j int) bool { return i == j }, -1, findIndexErrorPrefix+"expected func(int) bool, got func(int, int) bool: has 2 parameters, want 1", t)
 }
 
 func testFindIndex(slc, pred interface{}, target int, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -247,14 +301,14 @@ func TestSome(t *testing.T) {
 	// Some should fail
 	testSome(3, nil, false, someSliceError, t)
 	testSome([]int{1, 2, 3}, 3, false, someFunctionError, t)
-	testSome([]int{1, 2, 3}, func(b bool) bool { return b }, false, someTypeError, t)
-	testSome([]int{1, 2, 3}, func(i int) int { return i }, false, someTypeError, t)
-	testSome([]int{1, 2, 3}, func(i, j int) bool { return i == j }, false, someTypeError, t)
+	testSome([]int{1, 2, 3}, func(b bool) bool { return b }, false, someErrorPrefix+"expected func(int) bool, got func(bool) bool: parameter 0 is bool, slice element is int", t)
+	testSome([]int{1, 2, 3}, func(i int) int { return i }, false, someErrorPrefix+"expected func(int) bool, got func(int) int: result is int, want bool", t)
+	testSome([]int{1, 2, 3}, func(i, j int) bool { return i == j }, false, someErrorPrefix+"expected func(int) bool, got func(int, int) bool: has 2 parameters, want 1", t)
 }
 
 func testSome(slc, pred interface{}, target bool, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -278,14 +332,14 @@ func TestEvery(t *testing.T) {
 	// Some should fail
 	testEvery(3, nil, false, everySliceError, t)
 	testEvery([]int{1, 2, 3}, 3, false, everyFunctionError, t)
-	testEvery([]int{1, 2, 3}, func(b bool) bool { return b }, false, everyTypeError, t)
-	testEvery([]int{1, 2, 3}, func(i int) int { return i }, false, everyTypeError, t)
-	testEvery([]int{1, 2, 3}, func(i, j int) bool { return i == j }, false, everyTypeError, t)
+	testEvery([]int{1, 2, 3}, func(b bool) bool { return b }, false, everyErrorPrefix+"expected func(int) bool, got func(bool) bool: parameter 0 is bool, slice element is int", t)
+	testEvery([]int{1, 2, 3}, func(i int) int { return i }, false, everyErrorPrefix+"expected func(int) bool, got func(int) int: result is int, want bool", t)
+	testEvery([]int{1, 2, 3}, func(i, j int) bool { return i == j }, false, everyErrorPrefix+"expected func(int) bool, got func(int, int) bool: has 2 parameters, want 1", t)
 }
 
 func testEvery(slc, pred interface{}, target bool, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -308,15 +362,15 @@ func TestCount(t *testing.T) {
 	// Count should fail
 	testCount(3, nil, 0, countSliceError, t)
 	testCount([]int{1, 2, 3}, 3, 0, countFunctionError, t)
-	testCount([]int{1, 2, 3}, func(b bool) bool { return b }, 0, countTypeError, t)
-	testCount([]int{1, 2, 3}, func(i int) int { return i }, 0, countTypeError, t)
-	testCount([]int{1, 2, 3}, func(i, j int) bool { return i == j }, 0, countTypeError, t)
-	testCount([]int{1, 2, 3}, func(i int) (bool, bool) { return true, true }, 0, countTypeError, t)
+	testCount([]int{1, 2, 3}, func(b bool) bool { return b }, 0, countErrorPrefix+"expected func(int) bool, got func(bool) bool: parameter 0 is bool, slice element is int", t)
+	testCount([]int{1, 2, 3}, func(i int) int { return i }, 0, countErrorPrefix+"expected func(int) bool, got func(int) int: result is int, want bool", t)
+	testCount([]int{1, 2, 3}, func(i, j int) bool { return i == j }, 0, countErrorPrefix+"expected func(int) bool, got func(int, int) bool: has 2 parameters, want 1", t)
+	testCount([]int{1, 2, 3}, func(i int) (bool, bool) { return true, true }, 0, countErrorPrefix+"expected func(int) bool, got func(int) (bool, bool): has 2 results, want 1", t)
 }
 
 func testCount(slc, pred interface{}, target int, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -334,21 +388,26 @@ func TestMax(t *testing.T) {
 	testMax([]int{1, 2, 3}, func(i, j int) bool { return i > j }, 1, nil, t)
 	testMax([]int{1, 2, 3}, func(i, j int) bool { return true }, 3, nil, t)
 	testMax([]int{}, func(i, j int) bool { return true }, nil, nil, t)
+	testMax([]int{2, 3, 1}, nil, 3, nil, t)
+	testMax([]string{"b", "c", "a"}, nil, "c", nil, t)
+	testMax([]int{}, nil, nil, nil, t)
+	testMax([]int{1, 3, 2}, func(i, j interface{}) bool { return i.(int) < j.(int) }, 3, nil, t)
 
 	// Max should fail
 	testMax(3, nil, nil, maxSliceError, t)
 	testMax([]int{}, 3, nil, maxFunctionError, t)
-	testMax([]int{}, func(i, j bool) bool { return true }, nil, maxTypeError, t)
-	testMax([]int{}, func(i int, b bool) bool { return true }, nil, maxTypeError, t)
-	testMax([]int{}, func(b bool, j int) bool { return true }, nil, maxTypeError, t)
-	testMax([]int{}, func(i, j int) int { return 0 }, nil, maxTypeError, t)
-	testMax([]int{}, func(i, j, k int) bool { return true }, nil, maxTypeError, t)
-	testMax([]int{}, func(i, j int) (bool, bool) { return true, true }, nil, maxTypeError, t)
+	testMax([]int{}, func(i, j bool) bool { return true }, nil, maxErrorPrefix+"expected func(int, int) bool, got func(bool, bool) bool: parameter 0 is bool, slice element is int; parameter 1 is bool, slice element is int", t)
+	testMax([]int{}, func(i int, b bool) bool { return true }, nil, maxErrorPrefix+"expected func(int, int) bool, got func(int, bool) bool: parameter 1 is bool, slice element is int", t)
+	testMax([]int{}, func(b bool, j int) bool { return true }, nil, maxErrorPrefix+"expected func(int, int) bool, got func(bool, int) bool: parameter 0 is bool, slice element is int", t)
+	testMax([]int{}, func(i, j int) int { return 0 }, nil, maxErrorPrefix+"expected func(int, int) bool, got func(int, int) int: result is int, want bool", t)
+	testMax([]int{}, func(i, j, k int) bool { return true }, nil, maxErrorPrefix+"expected func(int, int) bool, got func(int, int, int) bool: has 3 parameters, want 2", t)
+	testMax([]int{}, func(i, j int) (bool, bool) { return true, true }, nil, maxErrorPrefix+"expected func(int, int) bool, got func(int, int) (bool, bool): has 2 results, want 1", t)
+	testMax([]bool{}, nil, nil, maxOrderError, t)
 }
 
 func testMax(slc, greater, target interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -367,21 +426,25 @@ func TestMin(t *testing.T) {
 	testMin([]int{1, 2, 3}, func(i, j int) bool { return true }, 3, nil, t)
 	testMin([]int{}, func(i, j int) bool { return true }, nil, nil, t)
 	testMin([]int{1}, func(i, j int) bool { return true }, 1, nil, t)
+	testMin([]int{2, 3, 1}, nil, 1, nil, t)
+	testMin([]int{2, 3, 1}, func(i, j interface{}) bool { return i.(int) < j.(int) }, 1, nil, t)
+	testMin([][2]int{{1, 2}, {0, 3}}, nil, [2]int{0, 3}, nil, t)
 
 	// Max should fail
 	testMin(3, nil, nil, minSliceError, t)
 	testMin([]int{}, 3, nil, minFunctionError, t)
-	testMin([]int{}, func(i, j bool) bool { return true }, nil, minTypeError, t)
-	testMin([]int{}, func(i int, b bool) bool { return true }, nil, minTypeError, t)
-	testMin([]int{}, func(b bool, j int) bool { return true }, nil, minTypeError, t)
-	testMin([]int{}, func(i, j int) int { return 0 }, nil, minTypeError, t)
-	testMin([]int{}, func(i, j, k int) bool { return true }, nil, minTypeError, t)
-	testMin([]int{}, func(i, j int) (bool, bool) { return true, true }, nil, minTypeError, t)
+	testMin([]int{}, func(i, j bool) bool { return true }, nil, minErrorPrefix+"expected func(int, int) bool, got func(bool, bool) bool: parameter 0 is bool, slice element is int; parameter 1 is bool, slice element is int", t)
+	testMin([]int{}, func(i int, b bool) bool { return true }, nil, minErrorPrefix+"expected func(int, int) bool, got func(int, bool) bool: parameter 1 is bool, slice element is int", t)
+	testMin([]int{}, func(b bool, j int) bool { return true }, nil, minErrorPrefix+"expected func(int, int) bool, got func(bool, int) bool: parameter 0 is bool, slice element is int", t)
+	testMin([]int{}, func(i, j int) int { return 0 }, nil, minErrorPrefix+"expected func(int, int) bool, got func(int, int) int: result is int, want bool", t)
+	testMin([]int{}, func(i, j, k int) bool { return true }, nil, minErrorPrefix+"expected func(int, int) bool, got func(int, int, int) bool: has 3 parameters, want 2", t)
+	testMin([]int{}, func(i, j int) (bool, bool) { return true, true }, nil, minErrorPrefix+"expected func(int, int) bool, got func(int, int) (bool, bool): has 2 results, want 1", t)
+	testMin([]map[int]int{}, nil, nil, minOrderError, t)
 }
 
 func testMin(slc, greater, target interface{}, err interface{}, t *testing.T) {
 	defer func() {
-		r := recover()
+		r := errorString(recover())
 		if !reflect.DeepEqual(r, err) {
 			t.Errorf("Expected error %v; got %v", err, r)
 		}
@@ -393,6 +456,16 @@ func testMin(slc, greater, target interface{}, err interface{}, t *testing.T) {
 	}
 }
 
+// errorString returns the message of an *Error,
+// so that panics can be compared against the
+// error strings below.
+func errorString(r interface{}) interface{} {
+	if e, ok := r.(*Error); ok {
+		return e.Error()
+	}
+	return r
+}
+
 // Since we don't get to see the strings written
 // as literals anywhere, do this so we can double-check
 // that the error strings were composed properly.
@@ -405,6 +478,10 @@ func testVerifyErrorStrings(t *testing.T) {
 		mapFunctionError,
 		mapTypeError,
 
+		mapToSliceError,
+		mapToFunctionError,
+		mapToResultError,
+
 		filterSliceError,
 		filterFunctionError,
 		filterTypeError,
@@ -446,10 +523,66 @@ func testVerifyErrorStrings(t *testing.T) {
 		maxSliceError,
 		maxFunctionError,
 		maxTypeError,
+		maxOrderError,
 
 		minSliceError,
 		minFunctionError,
 		minTypeError,
+		minOrderError,
+
+		mapValuesMapError,
+		mapValuesFunctionError,
+
+		mapEntriesMapError,
+		mapEntriesFunctionError,
+
+		filterMapMapError,
+		filterMapFunctionError,
+
+		keysMapError,
+		keysFunctionError,
+
+		valuesMapError,
+		valuesFunctionError,
+
+		foldMapMapError,
+		foldMapFunctionError,
+
+		mapChanChanError,
+		mapChanFunctionError,
+		mapChanBufferError,
+
+		filterChanChanError,
+		filterChanFunctionError,
+		filterChanBufferError,
+
+		mapSeqSliceError,
+		mapSeqFunctionError,
+
+		filterSeqSliceError,
+		filterSeqFunctionError,
+
+		rejectSeqSliceError,
+		rejectSeqFunctionError,
+
+		collectSliceError,
+
+		streamSliceError,
+
+		parallelMapSliceError,
+		parallelMapFunctionError,
+
+		parallelFilterSliceError,
+		parallelFilterFunctionError,
+
+		parallelCountSliceError,
+		parallelCountFunctionError,
+
+		parallelSomeSliceError,
+		parallelSomeFunctionError,
+
+		parallelEverySliceError,
+		parallelEveryFunctionError,
 	}
 	fmt.Println("Error strings:")
 	for _, s := range toPrint {
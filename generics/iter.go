@@ -0,0 +1,188 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+)
+
+// The functions in this file return iterator functions
+// of type func(yield func(U) bool), which can be ranged
+// over, or converted to an iter.Seq[U]. Their arguments
+// are checked when they are called, but pred is not
+// called until the iterator is, and then only for as
+// many elements as are needed. Each call to the iterator
+// visits the elements of slc afresh, so if slc is a
+// slice, changes made to it in the meantime are seen.
+
+//	func MapSeq(slc []T, pred func(T) U) func(yield func(U) bool)
+//
+// MapSeq returns an iterator which yields the
+// result of applying pred to each element of slc.
+func MapSeq(slc, pred interface{}) interface{} {
+	ret, err := MapSeqE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func MapSeqE(slc []T, pred func(T) U) (func(yield func(U) bool), error)
+//
+// MapSeqE is like MapSeq, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func MapSeqE(slc, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("MapSeq", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("MapSeq", NotFunction, slc, pred, nil)
+	}
+
+	fType := f.Type()
+
+	sig := mapSig(seq.elemParam())
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("MapSeq", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	return lazy(seq, fType.Out(0), func(v reflect.Value) (reflect.Value, bool) {
+		return f.Call([]reflect.Value{v})[0], true
+	}).Interface(), nil
+}
+
+//	func FilterSeq(slc []T, pred func(T) bool) func(yield func(T) bool)
+//
+// FilterSeq returns an iterator which yields
+// those elements of slc for which pred
+// returns true.
+func FilterSeq(slc, pred interface{}) interface{} {
+	ret, err := FilterSeqE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FilterSeqE(slc []T, pred func(T) bool) (func(yield func(T) bool), error)
+//
+// FilterSeqE is like FilterSeq, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func FilterSeqE(slc, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("FilterSeq", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("FilterSeq", NotFunction, slc, pred, nil)
+	}
+
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("FilterSeq", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	return lazy(seq, seq.elem, func(v reflect.Value) (reflect.Value, bool) {
+		return v, f.Call([]reflect.Value{v})[0].Bool()
+	}).Interface(), nil
+}
+
+//	func RejectSeq(slc []T, pred func(T) bool) func(yield func(T) bool)
+//
+// RejectSeq returns an iterator which yields
+// those elements of slc for which pred
+// returns false.
+func RejectSeq(slc, pred interface{}) interface{} {
+	ret, err := RejectSeqE(slc, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func RejectSeqE(slc []T, pred func(T) bool) (func(yield func(T) bool), error)
+//
+// RejectSeqE is like RejectSeq, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func RejectSeqE(slc, pred interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("RejectSeq", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("RejectSeq", NotFunction, slc, pred, nil)
+	}
+
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("RejectSeq", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	return lazy(seq, seq.elem, func(v reflect.Value) (reflect.Value, bool) {
+		return v, !f.Call([]reflect.Value{v})[0].Bool()
+	}).Interface(), nil
+}
+
+// lazy returns an iterator function over values
+// of type typ which yields the results of f for
+// which f returns true, applying it to each
+// element of seq in turn, and stops seq when
+// the caller's yield function returns false.
+func lazy(seq sequence, typ reflect.Type, f func(reflect.Value) (reflect.Value, bool)) reflect.Value {
+	yieldType := reflect.FuncOf([]reflect.Type{typ}, []reflect.Type{boolType}, false)
+	iterType := reflect.FuncOf([]reflect.Type{yieldType}, nil, false)
+	return reflect.MakeFunc(iterType, func(args []reflect.Value) []reflect.Value {
+		yield := args[0]
+		seq.each(func(i int, v reflect.Value) bool {
+			v, ok := f(v)
+			return !ok || yield.Call([]reflect.Value{v})[0].Bool()
+		})
+		return nil
+	})
+}
+
+//	func Collect(slc []T) []T
+//
+// Collect returns the elements of slc as a slice.
+// If slc is a slice, the result is a copy of it,
+// of the same type; otherwise, it is a []T (so
+// a []rune for a string).
+func Collect(slc interface{}) interface{} {
+	ret, err := CollectE(slc)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func CollectE(slc []T) ([]T, error)
+//
+// CollectE is like Collect, except that it returns
+// an *Error rather than panicking if slc is not a
+// valid sequence.
+func CollectE(slc interface{}) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("Collect", NotSlice, slc, nil, nil)
+	}
+
+	ret := seq.subset()
+	seq.each(func(i int, v reflect.Value) bool {
+		ret = reflect.Append(ret, v)
+		return true
+	})
+
+	return ret.Interface(), nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMapSeq(t *testing.T) {
+	seq, n := testIter(1, 2, 3)
+
+	// MapSeq should be lazy
+	ret := MapSeq(seq, strconv.Itoa)
+	if *n != 0 {
+		t.Errorf("Expected no values to be yielded before iterating; got %v", *n)
+	}
+	testIterResult(ret, []string{"1", "2", "3"}, t)
+	testIterResult(MapSeq("hé", func(r rune) string { return string(r) }), []string{"h", "é"}, t)
+	testIterResult(MapSeq([]int(nil), strconv.Itoa), []string(nil), t)
+
+	// MapSeq should panic
+	testMapFunc(func() interface{} { return MapSeq(3, strconv.Itoa) }, nil, mapSeqSliceError, t)
+	testMapFunc(func() interface{} { return MapSeq(seq, nil) }, nil, mapSeqFunctionError, t)
+	testMapFunc(func() interface{} { return MapSeq(seq, func(s string) int { return 0 }) }, nil,
+		mapSeqErrorPrefix+"expected func(int) U, got func(string) int: parameter 0 is string, sequence element is int", t)
+}
+
+func TestFilterSeq(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+
+	// FilterSeq and RejectSeq should succeed
+	testIterResult(FilterSeq([]int{1, 2, 3, 4}, isEven), []int{2, 4}, t)
+	testIterResult(RejectSeq([]int{1, 2, 3, 4}, isEven), []int{1, 3}, t)
+	testIterResult(FilterSeq(MapSeq([]int{1, 2, 3}, func(i int) int { return i * 2 }), isEven), []int{2, 4, 6}, t)
+
+	// FilterSeq and RejectSeq should panic
+	testMapFunc(func() interface{} { return FilterSeq(3, isEven) }, nil, filterSeqSliceError, t)
+	testMapFunc(func() interface{} { return FilterSeq([]int{}, 3) }, nil, filterSeqFunctionError, t)
+	testMapFunc(func() interface{} { return RejectSeq(3, isEven) }, nil, rejectSeqSliceError, t)
+	testMapFunc(func() interface{} { return RejectSeq([]string{}, isEven) }, nil,
+		rejectSeqErrorPrefix+"expected func(string) bool, got func(int) bool: parameter 0 is int, slice element is string", t)
+}
+
+func TestSeqEarlyStop(t *testing.T) {
+	seq, n := testIter(1, 2, 3, 4)
+	evens := FilterSeq(seq, func(i int) bool { return i%2 == 0 }).(func(func(int) bool))
+
+	// Stopping the returned iterator should
+	// stop the underlying one
+	var got []int
+	evens(func(i int) bool {
+		got = append(got, i)
+		return false
+	})
+	if !reflect.DeepEqual(got, []int{2}) || *n != 2 {
+		t.Errorf("Expected [2] after 2 values; got %v after %v", got, *n)
+	}
+
+	// Each call should iterate afresh
+	*n = 0
+	if ret := Find(evens, func(i int) bool { return i > 2 }); ret != 4 || *n != 4 {
+		t.Errorf("Expected 4 after 4 values; got %v after %v", ret, *n)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	type ints []int
+	slc := ints{1, 2, 3}
+	seq, _ := testIter(1, 2, 3)
+	arr := [2]string{"a", "b"}
+
+	// Collect should succeed
+	testMapFunc(func() interface{} { return Collect(slc) }, ints{1, 2, 3}, nil, t)
+	testMapFunc(func() interface{} { return Collect(seq) }, []int{1, 2, 3}, nil, t)
+	testMapFunc(func() interface{} { return Collect(&arr) }, []string{"a", "b"}, nil, t)
+	testMapFunc(func() interface{} { return Collect("hé") }, []rune{'h', 'é'}, nil, t)
+
+	// Collect should copy slices
+	ret := Collect(slc).(ints)
+	ret[0] = 4
+	if slc[0] != 1 {
+		t.Errorf("Expected Collect to copy its argument")
+	}
+
+	// Collect should panic
+	testMapFunc(func() interface{} { return Collect(map[int]int{}) }, nil, collectSliceError, t)
+	testErrorVariant(func() (interface{}, error) { return CollectE(nil) }, nil, NotSlice, t)
+}
+
+// testIterResult checks that seq is an iterator
+// function which yields the elements of expect.
+func testIterResult(seq, expect interface{}, t *testing.T) {
+	if seqArity(reflect.TypeOf(seq)) != 1 {
+		t.Errorf("Expected an iterator function; got %T", seq)
+		return
+	}
+	ret := reflect.Zero(reflect.TypeOf(expect))
+	iterate(reflect.ValueOf(seq), func(args []reflect.Value) bool {
+		ret = reflect.Append(ret, args[0])
+		return true
+	})
+	if !reflect.DeepEqual(ret.Interface(), expect) {
+		t.Errorf("Expected to yield %v; got %v", expect, ret)
+	}
+}
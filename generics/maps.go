@@ -0,0 +1,419 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"sort"
+)
+
+// The functions in this file operate on maps. Like
+// range loops over maps, they visit entries in an
+// unspecified order, so any functions passed to them
+// should not depend on the order in which they are
+// called.
+//
+// Each also accepts an iterator function over pairs,
+// such as an iter.Seq2[K, V], in place of m, and visits
+// its pairs in the order in which they are yielded.
+// MapValues and FilterMap, which return a map[K]...,
+// require K to be comparable; if the iterator yields
+// the same key more than once, the last pair wins.
+
+//	func MapValues(m map[K]V, pred func(V) W) map[K]W
+//
+// MapValues applies pred to each value in m, and
+// returns a map from each of m's keys to the
+// result of applying pred to its value.
+func MapValues(m, pred interface{}) interface{} {
+	ret, err := MapValuesE(m, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func MapValuesE(m map[K]V, pred func(V) W) (map[K]W, error)
+//
+// MapValuesE is like MapValues, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func MapValuesE(m, pred interface{}) (interface{}, error) {
+	p, ok := newPairs(m)
+	if !ok || !p.key.Comparable() {
+		return nil, newError("MapValues", NotMap, m, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("MapValues", NotFunction, m, pred, nil)
+	}
+
+	fType := f.Type()
+
+	sig := signature{[]sigParam{p.valueParam()}, []sigParam{typeParam("W")}}
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("MapValues", SignatureMismatch, m, pred, nil).because(sig, reason)
+	}
+
+	ret := reflect.MakeMapWithSize(reflect.MapOf(p.key, fType.Out(0)), p.len())
+
+	args := make([]reflect.Value, 1)
+	p.each(func(k, v reflect.Value) bool {
+		args[0] = v
+		ret.SetMapIndex(k, f.Call(args)[0])
+		return true
+	})
+
+	return ret.Interface(), nil
+}
+
+//	func MapEntries(m map[K]V, pred func(K, V) (K2, V2)) map[K2]V2
+//
+// MapEntries applies pred to each entry in m, and
+// returns a map of the keys and values which pred
+// returns. K2 must be comparable. If pred returns
+// the same key for more than one entry, which of
+// the values is kept is unspecified.
+func MapEntries(m, pred interface{}) interface{} {
+	ret, err := MapEntriesE(m, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func MapEntriesE(m map[K]V, pred func(K, V) (K2, V2)) (map[K2]V2, error)
+//
+// MapEntriesE is like MapEntries, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func MapEntriesE(m, pred interface{}) (interface{}, error) {
+	p, ok := newPairs(m)
+	if !ok {
+		return nil, newError("MapEntries", NotMap, m, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("MapEntries", NotFunction, m, pred, nil)
+	}
+
+	fType := f.Type()
+
+	sig := signature{[]sigParam{p.keyParam(), p.valueParam()}, []sigParam{typeParam("K2"), typeParam("V2")}}
+	reason := sig.check(fType)
+	if reason == "" && !fType.Out(0).Comparable() {
+		reason = "result 0 is " + fType.Out(0).String() + ", which is not comparable"
+	}
+	if reason != "" {
+		return nil, newError("MapEntries", SignatureMismatch, m, pred, nil).because(sig, reason)
+	}
+
+	ret := reflect.MakeMapWithSize(reflect.MapOf(fType.Out(0), fType.Out(1)), p.len())
+
+	args := make([]reflect.Value, 2)
+	p.each(func(k, v reflect.Value) bool {
+		args[0], args[1] = k, v
+		results := f.Call(args)
+		ret.SetMapIndex(results[0], results[1])
+		return true
+	})
+
+	return ret.Interface(), nil
+}
+
+//	func FilterMap(m map[K]V, pred func(K, V) bool) map[K]V
+//
+// FilterMap applies pred to each entry in m, and
+// returns a new map of the same type as m which
+// contains those entries for which pred returned
+// true.
+func FilterMap(m, pred interface{}) interface{} {
+	ret, err := FilterMapE(m, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FilterMapE(m map[K]V, pred func(K, V) bool) (map[K]V, error)
+//
+// FilterMapE is like FilterMap, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func FilterMapE(m, pred interface{}) (interface{}, error) {
+	p, ok := newPairs(m)
+	if !ok || !p.key.Comparable() {
+		return nil, newError("FilterMap", NotMap, m, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("FilterMap", NotFunction, m, pred, nil)
+	}
+
+	sig := signature{[]sigParam{p.keyParam(), p.valueParam()}, []sigParam{concrete(boolType, "")}}
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("FilterMap", SignatureMismatch, m, pred, nil).because(sig, reason)
+	}
+
+	ret := reflect.MakeMap(p.mapType())
+
+	args := make([]reflect.Value, 2)
+	p.each(func(k, v reflect.Value) bool {
+		args[0], args[1] = k, v
+		if f.Call(args)[0].Bool() {
+			ret.SetMapIndex(k, v)
+		}
+		return true
+	})
+
+	return ret.Interface(), nil
+}
+
+//	func Keys(m map[K]V, less func(K, K) bool) []K
+//
+// Keys returns the keys of m. If less is nil,
+// they are in the same order as in a range loop
+// over m; otherwise, they are sorted according
+// to less (less(a, b) returns (a < b)).
+func Keys(m, less interface{}) interface{} {
+	ret, err := KeysE(m, less)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func KeysE(m map[K]V, less func(K, K) bool) ([]K, error)
+//
+// KeysE is like Keys, except that it returns an
+// *Error rather than panicking if the types of
+// its arguments do not match.
+func KeysE(m, less interface{}) (interface{}, error) {
+	p, ok := newPairs(m)
+	if !ok {
+		return nil, newError("Keys", NotMap, m, less, nil)
+	}
+
+	f, err := lessFunc("Keys", p.keyParam(), m, less)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := reflect.MakeSlice(reflect.SliceOf(p.key), 0, p.len())
+	p.each(func(k, v reflect.Value) bool {
+		ret = reflect.Append(ret, k)
+		return true
+	})
+
+	sortValues(ret, f)
+	return ret.Interface(), nil
+}
+
+//	func Values(m map[K]V, less func(V, V) bool) []V
+//
+// Values returns the values in m. If less is
+// nil, they are in the same order as in a range
+// loop over m; otherwise, they are sorted
+// according to less (less(a, b) returns (a < b)).
+func Values(m, less interface{}) interface{} {
+	ret, err := ValuesE(m, less)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func ValuesE(m map[K]V, less func(V, V) bool) ([]V, error)
+//
+// ValuesE is like Values, except that it returns
+// an *Error rather than panicking if the types
+// of its arguments do not match.
+func ValuesE(m, less interface{}) (interface{}, error) {
+	p, ok := newPairs(m)
+	if !ok {
+		return nil, newError("Values", NotMap, m, less, nil)
+	}
+
+	f, err := lessFunc("Values", p.valueParam(), m, less)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := reflect.MakeSlice(reflect.SliceOf(p.elem), 0, p.len())
+	p.each(func(k, v reflect.Value) bool {
+		ret = reflect.Append(ret, v)
+		return true
+	})
+
+	sortValues(ret, f)
+	return ret.Interface(), nil
+}
+
+// lessFunc returns less as a reflect.Value, or the
+// invalid Value if less is nil. If less is not a
+// valid func(T, T) bool, where elem describes T,
+// lessFunc returns an *Error for fname instead.
+func lessFunc(fname string, elem sigParam, m, less interface{}) (reflect.Value, error) {
+	if less == nil {
+		return reflect.Value{}, nil
+	}
+
+	f := reflect.ValueOf(less)
+	if f.Kind() != reflect.Func {
+		return f, newError(fname, NotFunction, m, less, nil)
+	}
+
+	sig := lessSig(elem)
+	if reason := sig.check(f.Type()); reason != "" {
+		return f, newError(fname, SignatureMismatch, m, less, nil).because(sig, reason)
+	}
+	return f, nil
+}
+
+// sortValues sorts slice using less, as returned
+// by lessFunc, unless it is the invalid Value.
+func sortValues(slice, less reflect.Value) {
+	if !less.IsValid() {
+		return
+	}
+	args := make([]reflect.Value, 2)
+	sort.Slice(slice.Interface(), func(i, j int) bool {
+		args[0], args[1] = slice.Index(i), slice.Index(j)
+		return less.Call(args)[0].Bool()
+	})
+}
+
+//	func FoldMap(m map[K]V, zero U, pred func(K, V, U) U) U
+//
+// FoldMap applies pred to each entry in m, using
+// the previous call's return value as its third
+// argument, and zero for the first call, as Foldr
+// does. Since the order of the entries is
+// unspecified, pred should give the same result
+// regardless of order (as summing values does).
+func FoldMap(m, zero, pred interface{}) interface{} {
+	ret, err := FoldMapE(m, zero, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func FoldMapE(m map[K]V, zero U, pred func(K, V, U) U) (U, error)
+//
+// FoldMapE is like FoldMap, except that it returns
+// an *Error rather than panicking if the types of
+// its arguments do not match.
+func FoldMapE(m, zero, pred interface{}) (interface{}, error) {
+	p, ok := newPairs(m)
+	if !ok {
+		return nil, newError("FoldMap", NotMap, m, pred, zero)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("FoldMap", NotFunction, m, pred, zero)
+	}
+
+	fType := f.Type()
+
+	u := typeParam("U")
+	sig := signature{[]sigParam{p.keyParam(), p.valueParam(), u}, []sigParam{u}}
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("FoldMap", SignatureMismatch, m, pred, zero).because(sig, reason)
+	}
+
+	z, reason := zeroValue(reflect.ValueOf(zero), fType.In(2), "parameter 2")
+	if reason != "" {
+		if z.IsValid() {
+			u = concrete(z.Type(), "zero")
+			sig = signature{[]sigParam{p.keyParam(), p.valueParam(), u}, []sigParam{u}}
+		}
+		return nil, newError("FoldMap", ZeroMismatch, m, pred, zero).because(sig, reason)
+	}
+
+	args := make([]reflect.Value, 3)
+	args[2] = z
+	p.each(func(k, v reflect.Value) bool {
+		args[0], args[1] = k, v
+		args[2] = f.Call(args)[0]
+		return true
+	})
+
+	return args[2].Interface(), nil
+}
+
+// A pairs is the m argument to a function: a
+// map, or a non-nil iterator function over
+// pairs (iter.Seq2).
+type pairs struct {
+	v         reflect.Value
+	key, elem reflect.Type
+}
+
+func newPairs(m interface{}) (pairs, bool) {
+	v := reflect.ValueOf(m)
+	switch {
+	case v.Kind() == reflect.Map:
+		return pairs{v, v.Type().Key(), v.Type().Elem()}, true
+	case v.Kind() == reflect.Func && seqArity(v.Type()) == 2 && !v.IsNil():
+		yield := v.Type().In(0)
+		return pairs{v, yield.In(0), yield.In(1)}, true
+	}
+	return pairs{}, false
+}
+
+// len returns the number of entries in p if it is
+// a map, and 0 otherwise, for use as a capacity.
+func (p pairs) len() int {
+	if p.v.Kind() == reflect.Map {
+		return p.v.Len()
+	}
+	return 0
+}
+
+// mapType returns p's type if it is a map, and
+// map[K]V otherwise. p's key type must be
+// comparable.
+func (p pairs) mapType() reflect.Type {
+	if p.v.Kind() == reflect.Map {
+		return p.v.Type()
+	}
+	return reflect.MapOf(p.key, p.elem)
+}
+
+// each calls f with each key and value in p
+// until f returns false.
+func (p pairs) each(f func(k, v reflect.Value) bool) {
+	if p.v.Kind() == reflect.Func {
+		iterate(p.v, func(args []reflect.Value) bool {
+			return f(args[0], args[1])
+		})
+		return
+	}
+	iter := p.v.MapRange()
+	for iter.Next() {
+		if !f(iter.Key(), iter.Value()) {
+			return
+		}
+	}
+}
+
+func (p pairs) keyParam() sigParam {
+	if p.v.Kind() == reflect.Func {
+		return concrete(p.key, "sequence key")
+	}
+	return concrete(p.key, "map key")
+}
+
+func (p pairs) valueParam() sigParam {
+	if p.v.Kind() == reflect.Func {
+		return concrete(p.elem, "sequence value")
+	}
+	return concrete(p.elem, "map value")
+}
@@ -0,0 +1,180 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMapValues(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+
+	// MapValues should succeed
+	testMapFunc(func() interface{} { return MapValues(m, strconv.Itoa) }, map[string]string{"a": "1", "b": "2"}, nil, t)
+	testMapFunc(func() interface{} { return MapValues(map[string]int(nil), strconv.Itoa) }, map[string]string{}, nil, t)
+	testMapFunc(func() interface{} { return MapValues(m, func(i interface{}) bool { return i == 1 }) }, map[string]bool{"a": true, "b": false}, nil, t)
+
+	// MapValues should panic
+	testMapFunc(func() interface{} { return MapValues([]int{}, strconv.Itoa) }, nil, mapValuesMapError, t)
+	testMapFunc(func() interface{} { return MapValues(m, 3) }, nil, mapValuesFunctionError, t)
+	testMapFunc(func() interface{} { return MapValues(m, func(s string) int { return 0 }) }, nil,
+		mapValuesErrorPrefix+"expected func(int) W, got func(string) int: parameter 0 is string, map value is int", t)
+}
+
+func TestMapEntries(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+	swap := func(s string, i int) (int, string) { return i, s }
+
+	// MapEntries should succeed
+	testMapFunc(func() interface{} { return MapEntries(m, swap) }, map[int]string{1: "a", 2: "b"}, nil, t)
+	testMapFunc(func() interface{} { return MapEntries(m, func(s string, i int) (bool, int) { return true, 3 }) }, map[bool]int{true: 3}, nil, t)
+
+	// MapEntries should panic
+	testMapFunc(func() interface{} { return MapEntries(3, swap) }, nil, mapEntriesMapError, t)
+	testMapFunc(func() interface{} { return MapEntries(m, nil) }, nil, mapEntriesFunctionError, t)
+	testMapFunc(func() interface{} { return MapEntries(m, func(i int, s string) (int, string) { return i, s }) }, nil,
+		mapEntriesErrorPrefix+"expected func(string, int) (K2, V2), got func(int, string) (int, string): parameter 0 is int, map key is string; parameter 1 is string, map value is int", t)
+	testMapFunc(func() interface{} { return MapEntries(m, func(s string, i int) int { return i }) }, nil,
+		mapEntriesErrorPrefix+"expected func(string, int) (K2, V2), got func(string, int) int: has 1 result, want 2", t)
+	testMapFunc(func() interface{} { return MapEntries(m, func(s string, i int) ([]int, int) { return nil, i }) }, nil,
+		mapEntriesErrorPrefix+"expected func(string, int) (K2, V2), got func(string, int) ([]int, int): result 0 is []int, which is not comparable", t)
+}
+
+func TestFilterMap(t *testing.T) {
+	type config map[string]int
+	m := config{"a": 1, "b": 2, "c": 3}
+
+	// FilterMap should succeed
+	testMapFunc(func() interface{} { return FilterMap(m, func(s string, i int) bool { return i%2 == 1 }) }, config{"a": 1, "c": 3}, nil, t)
+	testMapFunc(func() interface{} { return FilterMap(m, func(s string, i int) bool { return s == "b" }) }, config{"b": 2}, nil, t)
+	testMapFunc(func() interface{} { return FilterMap(config(nil), func(s string, i int) bool { return true }) }, config{}, nil, t)
+
+	// FilterMap should panic
+	testMapFunc(func() interface{} { return FilterMap([]int{}, 3) }, nil, filterMapMapError, t)
+	testMapFunc(func() interface{} { return FilterMap(m, 3) }, nil, filterMapFunctionError, t)
+	testMapFunc(func() interface{} { return FilterMap(m, func(s string) bool { return true }) }, nil,
+		filterMapErrorPrefix+"expected func(string, int) bool, got func(string) bool: has 1 parameter, want 2", t)
+}
+
+func TestKeysValues(t *testing.T) {
+	m := map[string]int{"b": 1, "a": 3, "c": 2}
+	strLess := func(a, b string) bool { return a < b }
+	intLess := func(a, b int) bool { return a < b }
+
+	// Keys and Values should succeed
+	testMapFunc(func() interface{} { return Keys(m, strLess) }, []string{"a", "b", "c"}, nil, t)
+	testMapFunc(func() interface{} { return Values(m, intLess) }, []int{1, 2, 3}, nil, t)
+	testMapFunc(func() interface{} { return Values(m, func(a, b int) bool { return a > b }) }, []int{3, 2, 1}, nil, t)
+	testMapFunc(func() interface{} { return Keys(map[int]bool{}, nil) }, []int{}, nil, t)
+	testMapFunc(func() interface{} { return len(Keys(m, nil).([]string)) }, 3, nil, t)
+	testMapFunc(func() interface{} { return len(Values(m, nil).([]int)) }, 3, nil, t)
+
+	// Keys and Values should panic
+	testMapFunc(func() interface{} { return Keys(3, nil) }, nil, keysMapError, t)
+	testMapFunc(func() interface{} { return Keys(m, 3) }, nil, keysFunctionError, t)
+	testMapFunc(func() interface{} { return Keys(m, intLess) }, nil,
+		keysErrorPrefix+"expected func(string, string) bool, got func(int, int) bool: parameter 0 is int, map key is string; parameter 1 is int, map key is string", t)
+	testMapFunc(func() interface{} { return Values(3, nil) }, nil, valuesMapError, t)
+	testMapFunc(func() interface{} { return Values(m, 3) }, nil, valuesFunctionError, t)
+	testMapFunc(func() interface{} { return Values(m, strLess) }, nil,
+		valuesErrorPrefix+"expected func(int, int) bool, got func(string, string) bool: parameter 0 is string, map value is int; parameter 1 is string, map value is int", t)
+}
+
+func TestFoldMap(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	sum := func(s string, i, acc int) int { return acc + i }
+
+	// FoldMap should succeed
+	testMapFunc(func() interface{} { return FoldMap(m, 0, sum) }, 6, nil, t)
+	testMapFunc(func() interface{} { return FoldMap(map[string]int{}, 5, sum) }, 5, nil, t)
+	testMapFunc(func() interface{} {
+		return len(FoldMap(m, nil, func(s string, i int, acc []string) []string { return append(acc, s) }).([]string))
+	}, 3, nil, t)
+	testMapFunc(func() interface{} {
+		return FoldMap(m, nil, func(s string, i int, acc map[string]string) map[string]string {
+			if acc == nil {
+				acc = make(map[string]string)
+			}
+			acc[s] = fmt.Sprint(i)
+			return acc
+		})
+	}, map[string]string{"a": "1", "b": "2", "c": "3"}, nil, t)
+
+	// FoldMap should panic
+	testMapFunc(func() interface{} { return FoldMap(3, 0, sum) }, nil, foldMapMapError, t)
+	testMapFunc(func() interface{} { return FoldMap(m, 0, 3) }, nil, foldMapFunctionError, t)
+	testMapFunc(func() interface{} { return FoldMap(m, 0, func(s string, i, acc int) bool { return true }) }, nil,
+		foldMapErrorPrefix+"expected func(string, int, U) U, got func(string, int, int) bool: result is bool, parameter 2 is int", t)
+	testMapFunc(func() interface{} { return FoldMap(m, "", sum) }, nil,
+		foldMapErrorPrefix+"expected func(string, int, string) string, got func(string, int, int) int: zero is string, parameter 2 is int", t)
+}
+
+func TestPairs(t *testing.T) {
+	seq, n := testPairs("b", 1, "a", 2, "b", 3)
+	slices := func(yield func([]int, int) bool) { yield([]int{1}, 1) }
+	double := func(i int) int { return i * 2 }
+	less := func(a, b string) bool { return a < b }
+
+	// Iterators over pairs should be accepted
+	// in place of maps, in order
+	testMapFunc(func() interface{} { return MapValues(seq, double) }, map[string]int{"a": 4, "b": 6}, nil, t)
+	testMapFunc(func() interface{} {
+		return MapEntries(slices, func(k []int, v int) (int, int) { return k[0], v })
+	}, map[int]int{1: 1}, nil, t)
+	testMapFunc(func() interface{} { return FilterMap(seq, func(s string, i int) bool { return i > 1 }) }, map[string]int{"a": 2, "b": 3}, nil, t)
+	testMapFunc(func() interface{} { return Keys(seq, nil) }, []string{"b", "a", "b"}, nil, t)
+	testMapFunc(func() interface{} { return Keys(seq, less) }, []string{"a", "b", "b"}, nil, t)
+	testMapFunc(func() interface{} { return Values(seq, nil) }, []int{1, 2, 3}, nil, t)
+	testMapFunc(func() interface{} {
+		return FoldMap(seq, "", func(s string, i int, acc string) string { return acc + s })
+	}, "bab", nil, t)
+
+	*n = 0
+	Keys(seq, nil)
+	if *n != 3 {
+		t.Errorf("Expected 3 pairs to be yielded; got %v", *n)
+	}
+
+	// Keys which aren't comparable can't be
+	// used in a map, and iterators over single
+	// values aren't iterators over pairs
+	testMapFunc(func() interface{} { return MapValues(slices, double) }, nil, mapValuesMapError, t)
+	testMapFunc(func() interface{} { return FilterMap(slices, 3) }, nil, filterMapMapError, t)
+	testMapFunc(func() interface{} { return Keys(func(yield func(int) bool) {}, nil) }, nil, keysMapError, t)
+	testMapFunc(func() interface{} { return Values(seq, func(a, b string) bool { return a < b }) }, nil,
+		valuesErrorPrefix+"expected func(int, int) bool, got func(string, string) bool: parameter 0 is string, sequence value is int; parameter 1 is string, sequence value is int", t)
+}
+
+// testPairs returns an iterator function over
+// pairs of alternating keys and values, and a
+// pointer to the number of pairs it has yielded.
+func testPairs(kvs ...interface{}) (func(func(string, int) bool), *int) {
+	n := new(int)
+	return func(yield func(string, int) bool) {
+		for i := 0; i < len(kvs); i += 2 {
+			*n++
+			if !yield(kvs[i].(string), kvs[i+1].(int)) {
+				return
+			}
+		}
+	}, n
+}
+
+func testMapFunc(f func() interface{}, expect interface{}, err interface{}, t *testing.T) {
+	defer func() {
+		r := errorString(recover())
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	ret := f()
+	if !reflect.DeepEqual(ret, expect) {
+		t.Errorf("Expected result %v; got %v", expect, ret)
+	}
+}
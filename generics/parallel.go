@@ -0,0 +1,386 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+)
+
+// The functions in this file call pred on up to workers
+// goroutines at once, which is worthwhile when pred is
+// slow (for example, because it does I/O); pred must be
+// safe to call concurrently. If workers is less than 1,
+// runtime.GOMAXPROCS(0) goroutines are used. Their
+// results are the same as those of the corresponding
+// sequential functions, in the same order, regardless
+// of the order in which the calls to pred finish. The
+// elements of slc are read, and iterators are run, on
+// the calling goroutine.
+//
+// If pred panics, no further calls to pred are started,
+// and once those already running have returned, the
+// function panics on the calling goroutine with a
+// *PanicError giving the element's index. As with the
+// other E variants, the E variants of these functions
+// do not recover such panics.
+
+// A PanicError is the value with which the functions
+// in this file panic if pred panics.
+type PanicError struct {
+	// The function which was called, without the
+	// package name or E suffix (for example,
+	// "ParallelMap" for both ParallelMap and
+	// ParallelMapE).
+	Func string
+	// The index of the element for which pred
+	// panicked, as FindIndex would give it, or,
+	// if pred panicked for more than one element,
+	// the lowest such index.
+	Index int
+	// The value with which pred panicked, and the
+	// stack of the goroutine on which it did.
+	Value interface{}
+	Stack []byte
+}
+
+// Error returns a message of the form
+//
+//	generics.ParallelMap: pred panicked at index 3: runtime error: index out of range [3] with length 3
+func (e *PanicError) Error() string {
+	return packageNamePrefix + e.Func + ": pred panicked at index " + strconv.Itoa(e.Index) + ": " + fmt.Sprint(e.Value)
+}
+
+// Unwrap returns e's Value if it is an error, so
+// that errors.Is and errors.As can inspect it.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+//	func ParallelMap(slc []T, pred func(T) U, workers int) []U
+//
+// ParallelMap is like Map, except that it calls
+// pred on up to workers goroutines at once.
+func ParallelMap(slc, pred interface{}, workers int) interface{} {
+	ret, err := ParallelMapE(slc, pred, workers)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func ParallelMapE(slc []T, pred func(T) U, workers int) ([]U, error)
+//
+// ParallelMapE is like ParallelMap, except that it
+// returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func ParallelMapE(slc, pred interface{}, workers int) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("ParallelMap", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("ParallelMap", NotFunction, slc, pred, nil)
+	}
+
+	fType := f.Type()
+
+	sig := mapSig(seq.elemParam())
+	if reason := sig.check(fType); reason != "" {
+		return nil, newError("ParallelMap", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	jobs := parallel("ParallelMap", seq, f, workers, nil)
+
+	ret := reflect.MakeSlice(reflect.SliceOf(fType.Out(0)), len(jobs), len(jobs))
+	for i, j := range jobs {
+		ret.Index(i).Set(j.result)
+	}
+
+	return ret.Interface(), nil
+}
+
+//	func ParallelFilter(slc []T, pred func(T) bool, workers int) []T
+//
+// ParallelFilter is like Filter, except that it
+// calls pred on up to workers goroutines at once.
+func ParallelFilter(slc, pred interface{}, workers int) interface{} {
+	ret, err := ParallelFilterE(slc, pred, workers)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func ParallelFilterE(slc []T, pred func(T) bool, workers int) ([]T, error)
+//
+// ParallelFilterE is like ParallelFilter, except
+// that it returns an *Error rather than panicking
+// if the types of its arguments do not match.
+func ParallelFilterE(slc, pred interface{}, workers int) (interface{}, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return nil, newError("ParallelFilter", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return nil, newError("ParallelFilter", NotFunction, slc, pred, nil)
+	}
+
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return nil, newError("ParallelFilter", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	ret := seq.subset()
+	for _, j := range parallel("ParallelFilter", seq, f, workers, nil) {
+		if j.result.Bool() {
+			ret = reflect.Append(ret, j.v)
+		}
+	}
+
+	return seq.result(ret), nil
+}
+
+//	func ParallelCount(slc []T, pred func(T) bool, workers int) int
+//
+// ParallelCount is like Count, except that it
+// calls pred on up to workers goroutines at once.
+func ParallelCount(slc, pred interface{}, workers int) int {
+	ret, err := ParallelCountE(slc, pred, workers)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func ParallelCountE(slc []T, pred func(T) bool, workers int) (int, error)
+//
+// ParallelCountE is like ParallelCount, except that
+// it returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func ParallelCountE(slc, pred interface{}, workers int) (int, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return 0, newError("ParallelCount", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return 0, newError("ParallelCount", NotFunction, slc, pred, nil)
+	}
+
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return 0, newError("ParallelCount", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	ret := 0
+	for _, j := range parallel("ParallelCount", seq, f, workers, nil) {
+		if j.result.Bool() {
+			ret++
+		}
+	}
+
+	return ret, nil
+}
+
+//	func ParallelSome(slc []T, pred func(T) bool, workers int) bool
+//
+// ParallelSome is like Some, except that it calls
+// pred on up to workers goroutines at once. Once
+// pred has returned true, no further calls to pred
+// are started, and no further elements are read.
+func ParallelSome(slc, pred interface{}, workers int) bool {
+	ret, err := ParallelSomeE(slc, pred, workers)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func ParallelSomeE(slc []T, pred func(T) bool, workers int) (bool, error)
+//
+// ParallelSomeE is like ParallelSome, except that
+// it returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func ParallelSomeE(slc, pred interface{}, workers int) (bool, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return false, newError("ParallelSome", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return false, newError("ParallelSome", NotFunction, slc, pred, nil)
+	}
+
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return false, newError("ParallelSome", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	isTrue := func(result reflect.Value) bool { return result.Bool() }
+	for _, j := range parallel("ParallelSome", seq, f, workers, isTrue) {
+		if j.result.IsValid() && j.result.Bool() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+//	func ParallelEvery(slc []T, pred func(T) bool, workers int) bool
+//
+// ParallelEvery is like Every, except that it calls
+// pred on up to workers goroutines at once. Once
+// pred has returned false, no further calls to pred
+// are started, and no further elements are read.
+func ParallelEvery(slc, pred interface{}, workers int) bool {
+	ret, err := ParallelEveryE(slc, pred, workers)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func ParallelEveryE(slc []T, pred func(T) bool, workers int) (bool, error)
+//
+// ParallelEveryE is like ParallelEvery, except that
+// it returns an *Error rather than panicking if the
+// types of its arguments do not match.
+func ParallelEveryE(slc, pred interface{}, workers int) (bool, error) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return false, newError("ParallelEvery", NotSlice, slc, pred, nil)
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return false, newError("ParallelEvery", NotFunction, slc, pred, nil)
+	}
+
+	sig := predicateSig(seq.elemParam())
+	if reason := sig.check(f.Type()); reason != "" {
+		return false, newError("ParallelEvery", SignatureMismatch, slc, pred, nil).because(sig, reason)
+	}
+
+	isFalse := func(result reflect.Value) bool { return !result.Bool() }
+	for _, j := range parallel("ParallelEvery", seq, f, workers, isFalse) {
+		if j.result.IsValid() && !j.result.Bool() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// A job is a call to pred for one element.
+type job struct {
+	// The element's index, as passed by each
+	i int
+	v reflect.Value
+	// pred's result, which is invalid if the
+	// call was never made
+	result reflect.Value
+}
+
+// parallel calls f with each element of seq on
+// up to workers goroutines at once, and returns a
+// job for each element which was read, in order.
+// If stop is not nil, and returns true for one of
+// f's results, parallel stops reading elements and
+// starting calls. If f panics, parallel panics with
+// a *PanicError for fname once all of the calls
+// which were started have returned.
+func parallel(fname string, seq sequence, f reflect.Value, workers int, stop func(result reflect.Value) bool) []*job {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		jobs   []*job
+		ch     = make(chan *job)
+		cancel = make(chan struct{})
+		once   sync.Once
+		wg     sync.WaitGroup
+
+		mu       sync.Mutex
+		panicked *PanicError
+	)
+	stopAll := func() { once.Do(func() { close(cancel) }) }
+
+	call := func(j *job, args []reflect.Value) {
+		defer func() {
+			if r := recover(); r != nil {
+				mu.Lock()
+				if panicked == nil || j.i < panicked.Index {
+					panicked = &PanicError{Func: fname, Index: j.i, Value: r, Stack: debug.Stack()}
+				}
+				mu.Unlock()
+				stopAll()
+			}
+		}()
+		args[0] = j.v
+		j.result = f.Call(args)[0]
+		if stop != nil && stop(j.result) {
+			stopAll()
+		}
+	}
+
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			args := make([]reflect.Value, 1)
+			for j := range ch {
+				select {
+				case <-cancel:
+					// Drain ch without starting calls
+				default:
+					call(j, args)
+				}
+			}
+		}()
+	}
+
+	func() {
+		// Even if seq panics, let the workers exit
+		defer func() {
+			close(ch)
+			wg.Wait()
+		}()
+		seq.each(func(i int, v reflect.Value) bool {
+			// Prefer stopping to sending once both
+			// are possible
+			select {
+			case <-cancel:
+				return false
+			default:
+			}
+			j := &job{i: i, v: v}
+			select {
+			case ch <- j:
+				jobs = append(jobs, j)
+				return true
+			case <-cancel:
+				return false
+			}
+		})
+	}()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+	return jobs
+}
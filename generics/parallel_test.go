@@ -0,0 +1,202 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelMap(t *testing.T) {
+	slc := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	seq, _ := testIter(1, 2, 3)
+
+	// Later elements finish first, but the
+	// results should be in order
+	slow := func(i int) string {
+		time.Sleep(time.Duration(len(slc)-i) * time.Millisecond)
+		return strconv.Itoa(i)
+	}
+
+	// ParallelMap should succeed
+	testMapFunc(func() interface{} { return ParallelMap(slc, slow, 4) }, []string{"1", "2", "3", "4", "5", "6", "7", "8"}, nil, t)
+	testMapFunc(func() interface{} { return ParallelMap(slc[:3], strconv.Itoa, 0) }, []string{"1", "2", "3"}, nil, t)
+	testMapFunc(func() interface{} { return ParallelMap(seq, strconv.Itoa, 2) }, []string{"1", "2", "3"}, nil, t)
+	testMapFunc(func() interface{} { return ParallelMap("hé", func(r rune) string { return string(r) }, 2) }, []string{"h", "é"}, nil, t)
+	testMapFunc(func() interface{} { return ParallelMap([]int(nil), strconv.Itoa, 2) }, []string{}, nil, t)
+
+	// ParallelMap should panic
+	testMapFunc(func() interface{} { return ParallelMap(3, strconv.Itoa, 2) }, nil, parallelMapSliceError, t)
+	testMapFunc(func() interface{} { return ParallelMap(slc, 3, 2) }, nil, parallelMapFunctionError, t)
+	testMapFunc(func() interface{} { return ParallelMap(slc, func(s string) int { return 0 }, 2) }, nil,
+		parallelMapErrorPrefix+"expected func(int) U, got func(string) int: parameter 0 is string, slice element is int", t)
+}
+
+func TestParallelWorkers(t *testing.T) {
+	var running, most int32
+	var mu sync.Mutex
+	pred := func(i int) bool {
+		n := atomic.AddInt32(&running, 1)
+		mu.Lock()
+		if n > most {
+			most = n
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return true
+	}
+
+	// No more than workers calls should run at once
+	for _, workers := range []int{1, 3} {
+		most = 0
+		if n := ParallelCount(make([]int, 20), pred, workers); n != 20 {
+			t.Errorf("Expected count 20; got %v", n)
+		}
+		if most > int32(workers) {
+			t.Errorf("Expected at most %v concurrent calls; got %v", workers, most)
+		}
+	}
+}
+
+func TestParallelFilter(t *testing.T) {
+	type ints []int
+	slc := ints{1, 2, 3, 4, 5, 6}
+	isEven := func(i int) bool { return i%2 == 0 }
+	isUpper := func(r rune) bool { return 'A' <= r && r <= 'Z' }
+
+	// ParallelFilter and ParallelCount should succeed
+	testMapFunc(func() interface{} { return ParallelFilter(slc, isEven, 3) }, ints{2, 4, 6}, nil, t)
+	testMapFunc(func() interface{} { return ParallelFilter([3]int{1, 2, 3}, isEven, 3) }, []int{2}, nil, t)
+	testMapFunc(func() interface{} { return ParallelFilter("HéLlo", isUpper, 2) }, "HL", nil, t)
+	testMapFunc(func() interface{} { return ParallelCount(slc, isEven, 3) }, 3, nil, t)
+	testMapFunc(func() interface{} { return ParallelCount(ints{}, isEven, 3) }, 0, nil, t)
+
+	// ParallelFilter and ParallelCount should panic
+	testMapFunc(func() interface{} { return ParallelFilter(3, isEven, 2) }, nil, parallelFilterSliceError, t)
+	testMapFunc(func() interface{} { return ParallelFilter(slc, nil, 2) }, nil, parallelFilterFunctionError, t)
+	testMapFunc(func() interface{} { return ParallelCount(nil, isEven, 2) }, nil, parallelCountSliceError, t)
+	testMapFunc(func() interface{} { return ParallelCount(slc, strconv.Itoa, 2) }, nil,
+		parallelCountErrorPrefix+"expected func(int) bool, got func(int) string: result is string, want bool", t)
+}
+
+func TestParallelSomeEvery(t *testing.T) {
+	slc := []int{1, 2, 3, 4, 5, 6}
+	isEven := func(i int) bool { return i%2 == 0 }
+	isPositive := func(i int) bool { return i > 0 }
+
+	// ParallelSome and ParallelEvery should succeed
+	testMapFunc(func() interface{} { return ParallelSome(slc, isEven, 3) }, true, nil, t)
+	testMapFunc(func() interface{} { return ParallelSome([]int{1, 3}, isEven, 3) }, false, nil, t)
+	testMapFunc(func() interface{} { return ParallelSome([]int{}, isEven, 3) }, false, nil, t)
+	testMapFunc(func() interface{} { return ParallelEvery(slc, isPositive, 3) }, true, nil, t)
+	testMapFunc(func() interface{} { return ParallelEvery(slc, isEven, 3) }, false, nil, t)
+	testMapFunc(func() interface{} { return ParallelEvery([]int{}, isEven, 3) }, true, nil, t)
+
+	// ParallelSome and ParallelEvery should panic
+	testMapFunc(func() interface{} { return ParallelSome(3, isEven, 2) }, nil, parallelSomeSliceError, t)
+	testMapFunc(func() interface{} { return ParallelSome(slc, 3, 2) }, nil, parallelSomeFunctionError, t)
+	testMapFunc(func() interface{} { return ParallelEvery(3, isEven, 2) }, nil, parallelEverySliceError, t)
+	testMapFunc(func() interface{} { return ParallelEvery(slc, func(s string) bool { return true }, 2) }, nil,
+		parallelEveryErrorPrefix+"expected func(int) bool, got func(string) bool: parameter 0 is string, slice element is int", t)
+
+	// Once the result is known, no further calls
+	// should start, and the iterator should stop
+	var calls int32
+	count := func(result bool) func(int) bool {
+		return func(int) bool {
+			atomic.AddInt32(&calls, 1)
+			return result
+		}
+	}
+	seq, n := testIter(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	if !ParallelSome(seq, count(true), 1) || calls != 1 || *n > 3 {
+		t.Errorf("Expected ParallelSome to stop after 1 call; got %v calls after %v values", calls, *n)
+	}
+	calls, *n = 0, 0
+	if ParallelEvery(seq, count(false), 1) || calls != 1 || *n > 3 {
+		t.Errorf("Expected ParallelEvery to stop after 1 call; got %v calls after %v values", calls, *n)
+	}
+}
+
+func TestParallelPanic(t *testing.T) {
+	errBad := errors.New("bad element")
+	slc := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	var calls int32
+	pred := func(i int) bool {
+		atomic.AddInt32(&calls, 1)
+		if i >= 3 {
+			panic(errBad)
+		}
+		return true
+	}
+
+	// The panic should be re-raised on this
+	// goroutine, for the first element which
+	// panicked, and stop further calls
+	e := testParallelPanic(func() { ParallelCount(slc, pred, 1) }, t)
+	if e != nil {
+		if e.Func != "ParallelCount" || e.Index != 3 || e.Value != errBad || len(e.Stack) == 0 {
+			t.Errorf("Expected a panic for index 3; got %#v", e)
+		}
+		if !errors.Is(e, errBad) {
+			t.Errorf("Expected %v to wrap %v", e, errBad)
+		}
+		if str := "generics.ParallelCount: pred panicked at index 3: bad element"; e.Error() != str {
+			t.Errorf("Expected error string %q; got %q", str, e.Error())
+		}
+	}
+	if calls != 4 {
+		t.Errorf("Expected 4 calls; got %v", calls)
+	}
+
+	// Indices should be those FindIndex gives,
+	// and E variants should not recover panics
+	e = testParallelPanic(func() {
+		ParallelMapE("aéb", func(r rune) rune {
+			if r == 'b' {
+				panic("b")
+			}
+			return r
+		}, 3)
+	}, t)
+	if e != nil && (e.Func != "ParallelMap" || e.Index != 3 || e.Value != "b") {
+		t.Errorf("Expected a panic for index 3; got %#v", e)
+	}
+}
+
+// testParallelPanic calls f, which should panic
+// with a *PanicError, and returns it.
+func testParallelPanic(f func(), t *testing.T) (e *PanicError) {
+	defer func() {
+		r := recover()
+		var ok bool
+		if e, ok = r.(*PanicError); !ok {
+			t.Errorf("Expected a *PanicError; got %#v", r)
+		}
+	}()
+	f()
+	return nil
+}
+
+func TestParallelErrorVariants(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+	slc := []int{1, 2, 3}
+
+	testErrorVariant(func() (interface{}, error) { return ParallelMapE(slc, isEven, 2) }, []bool{false, true, false}, 0, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelMapE(slc, 3, 2) }, nil, NotFunction, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelFilterE(slc, isEven, 2) }, []int{2}, 0, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelFilterE(3, isEven, 2) }, nil, NotSlice, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelCountE(slc, isEven, 2) }, 1, 0, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelCountE(slc, strconv.Itoa, 2) }, 0, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelSomeE(slc, isEven, 2) }, true, 0, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelSomeE(slc, nil, 2) }, false, NotFunction, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelEveryE(slc, isEven, 2) }, false, 0, t)
+	testErrorVariant(func() (interface{}, error) { return ParallelEveryE("a", isEven, 2) }, false, SignatureMismatch, t)
+}
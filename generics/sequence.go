@@ -0,0 +1,206 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"unicode/utf8"
+)
+
+var runeType = reflect.TypeOf(rune(0))
+
+// A sequence is the slc argument to a function:
+// a slice, an array, a non-nil pointer to an
+// array, a string, whose elements are its runes,
+// or a non-nil iterator function (iter.Seq).
+type sequence struct {
+	// For pointers to arrays, the array itself
+	v    reflect.Value
+	typ  reflect.Type
+	elem reflect.Type
+}
+
+func newSequence(slc interface{}) (sequence, bool) {
+	v := reflect.ValueOf(slc)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return sequence{v, v.Type(), v.Type().Elem()}, true
+	case reflect.String:
+		return sequence{v, v.Type(), runeType}, true
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Array && !v.IsNil() {
+			return sequence{v.Elem(), v.Type(), v.Type().Elem().Elem()}, true
+		}
+	case reflect.Func:
+		if seqArity(v.Type()) == 1 && !v.IsNil() {
+			return sequence{v, v.Type(), v.Type().In(0).In(0)}, true
+		}
+	}
+	return sequence{}, false
+}
+
+// seqArity returns 1 if typ is an iterator
+// function over single values, such as an
+// iter.Seq (func(yield func(T) bool)), 2 if
+// it is one over pairs, such as an iter.Seq2
+// (func(yield func(K, V) bool)), and 0 if it
+// is neither.
+func seqArity(typ reflect.Type) int {
+	if typ.Kind() != reflect.Func || typ.NumIn() != 1 || typ.NumOut() != 0 || typ.IsVariadic() {
+		return 0
+	}
+	yield := typ.In(0)
+	if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0) != boolType || yield.IsVariadic() {
+		return 0
+	}
+	if n := yield.NumIn(); n == 1 || n == 2 {
+		return n
+	}
+	return 0
+}
+
+// iterate calls the iterator function seq with
+// a yield function which calls f with the values
+// it is passed and returns f's result. Once f
+// has returned false, the yield function returns
+// false without calling f, in case seq ignores
+// its result.
+func iterate(seq reflect.Value, f func(args []reflect.Value) bool) {
+	stopped := false
+	yield := reflect.MakeFunc(seq.Type().In(0), func(args []reflect.Value) []reflect.Value {
+		if !stopped {
+			stopped = !f(args)
+		}
+		return []reflect.Value{reflect.ValueOf(!stopped)}
+	})
+	seq.Call([]reflect.Value{yield})
+}
+
+// isIter returns whether s is an iterator
+// function, whose length isn't known in advance.
+func (s sequence) isIter() bool {
+	return s.v.Kind() == reflect.Func
+}
+
+// elemParam returns s's element type for
+// use in a signature.
+func (s sequence) elemParam() sigParam {
+	switch s.v.Kind() {
+	case reflect.Array:
+		return concrete(s.elem, "array element")
+	case reflect.String:
+		return concrete(s.elem, "string element")
+	case reflect.Func:
+		return concrete(s.elem, "sequence element")
+	}
+	return concrete(s.elem, "slice element")
+}
+
+// len returns the number of elements in s, which
+// must not be an iterator function.
+func (s sequence) len() int {
+	if s.v.Kind() == reflect.String {
+		return utf8.RuneCountInString(s.v.String())
+	}
+	return s.v.Len()
+}
+
+// cap returns the capacity for a slice of
+// results, one per element of s; this is
+// s's capacity if it is a slice.
+func (s sequence) cap() int {
+	if s.v.Kind() == reflect.Slice {
+		return s.v.Cap()
+	}
+	return s.len()
+}
+
+// each calls f with the index and value of each
+// element of s in turn until f returns false.
+// For strings, the index is the byte offset of
+// the rune, as in a range loop. For iterator
+// functions, it is the number of values which
+// came before, and returning false stops the
+// iterator.
+func (s sequence) each(f func(i int, v reflect.Value) bool) {
+	if s.isIter() {
+		i := 0
+		iterate(s.v, func(args []reflect.Value) bool {
+			i++
+			return f(i-1, args[0])
+		})
+		return
+	}
+	if s.v.Kind() == reflect.String {
+		for i, r := range s.v.String() {
+			if !f(i, reflect.ValueOf(r)) {
+				return
+			}
+		}
+		return
+	}
+	for i := 0; i < s.v.Len(); i++ {
+		if !f(i, s.v.Index(i)) {
+			return
+		}
+	}
+}
+
+// reverse is like each, except that it visits
+// the elements in reverse order. Iterator
+// functions are run to completion first.
+func (s sequence) reverse(f func(i int, v reflect.Value) bool) {
+	if s.isIter() {
+		var vals []reflect.Value
+		s.each(func(i int, v reflect.Value) bool {
+			vals = append(vals, v)
+			return true
+		})
+		for i := len(vals) - 1; i >= 0; i-- {
+			if !f(i, vals[i]) {
+				return
+			}
+		}
+		return
+	}
+	if s.v.Kind() == reflect.String {
+		str := s.v.String()
+		for i := len(str); i > 0; {
+			r, size := utf8.DecodeLastRuneInString(str[:i])
+			i -= size
+			if !f(i, reflect.ValueOf(r)) {
+				return
+			}
+		}
+		return
+	}
+	for i := s.v.Len() - 1; i >= 0; i-- {
+		if !f(i, s.v.Index(i)) {
+			return
+		}
+	}
+}
+
+// subset returns an empty slice to which elements
+// of s can be appended to form a subset of s, to
+// be passed to result. If s is a slice, it has
+// the same type as s; otherwise, it is a slice
+// of s's element type.
+func (s sequence) subset() reflect.Value {
+	if s.typ.Kind() == reflect.Slice {
+		return reflect.MakeSlice(s.typ, 0, 0)
+	}
+	return reflect.MakeSlice(reflect.SliceOf(s.elem), 0, 0)
+}
+
+// result converts a subset of s, as returned by
+// subset, to the type which Filter and Reject
+// return; this is s's type if s is a string.
+func (s sequence) result(subset reflect.Value) interface{} {
+	if s.typ.Kind() == reflect.String {
+		return reflect.ValueOf(string(subset.Interface().([]rune))).Convert(s.typ).Interface()
+	}
+	return subset.Interface()
+}
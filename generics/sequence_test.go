@@ -0,0 +1,157 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testString string
+
+func TestSequence(t *testing.T) {
+	arr := [3]int{1, 2, 3}
+	testSequence([]int{1, 2, 3}, []int{0, 1, 2}, []interface{}{1, 2, 3}, "slice element", t)
+	testSequence(arr, []int{0, 1, 2}, []interface{}{1, 2, 3}, "array element", t)
+	testSequence(&arr, []int{0, 1, 2}, []interface{}{1, 2, 3}, "array element", t)
+	testSequence("aé😀b", []int{0, 1, 3, 7}, []interface{}{'a', 'é', '😀', 'b'}, "string element", t)
+	testSequence(testString("ab"), []int{0, 1}, []interface{}{'a', 'b'}, "string element", t)
+	testSequence("", nil, nil, "string element", t)
+	seq, _ := testIter(1, 2, 3)
+	testSequence(seq, []int{0, 1, 2}, []interface{}{1, 2, 3}, "sequence element", t)
+
+	for _, slc := range []interface{}{nil, 3, (*[3]int)(nil), new(int), map[int]int{},
+		(func(func(int) bool))(nil), func(func(int)) {}, func(func(int) bool) bool { return true }} {
+		if _, ok := newSequence(slc); ok {
+			t.Errorf("Expected %#v not to be a sequence", slc)
+		}
+	}
+}
+
+func testSequence(slc interface{}, indices []int, values []interface{}, elemName string, t *testing.T) {
+	seq, ok := newSequence(slc)
+	if !ok {
+		t.Errorf("Expected %#v to be a sequence", slc)
+		return
+	}
+	if p := seq.elemParam(); p.name != elemName {
+		t.Errorf("Expected element name %q; got %q", elemName, p.name)
+	}
+	if !seq.isIter() && seq.len() != len(values) {
+		t.Errorf("Expected length %v; got %v", len(values), seq.len())
+	}
+
+	var is []int
+	var vs []interface{}
+	seq.each(func(i int, v reflect.Value) bool {
+		is = append(is, i)
+		vs = append(vs, v.Interface())
+		return true
+	})
+	if !reflect.DeepEqual(is, indices) || !reflect.DeepEqual(vs, values) {
+		t.Errorf("Expected indices %v and values %v; got %v and %v", indices, values, is, vs)
+	}
+
+	is, vs = nil, nil
+	seq.reverse(func(i int, v reflect.Value) bool {
+		is = append([]int{i}, is...)
+		vs = append([]interface{}{v.Interface()}, vs...)
+		return true
+	})
+	if !reflect.DeepEqual(is, indices) || !reflect.DeepEqual(vs, values) {
+		t.Errorf("Expected reversed indices %v and values %v; got %v and %v", indices, values, is, vs)
+	}
+
+	// Stopping early
+	n := 0
+	seq.each(func(i int, v reflect.Value) bool {
+		n++
+		return false
+	})
+	if len(values) > 0 && n != 1 {
+		t.Errorf("Expected each to stop after 1 call; got %v", n)
+	}
+}
+
+func TestSequenceFunctions(t *testing.T) {
+	arr := [4]int{1, 2, 3, 4}
+	isEven := func(i int) bool { return i%2 == 0 }
+	isUpper := func(r rune) bool { return 'A' <= r && r <= 'Z' }
+
+	testSequenceFunction(Map(arr, isEven), []bool{false, true, false, true}, t)
+	testSequenceFunction(Map(&arr, isEven), []bool{false, true, false, true}, t)
+	testSequenceFunction(Map("hé", func(r rune) string { return string(r) }), []string{"h", "é"}, t)
+	testSequenceFunction(cap(Map(arr[:2], isEven).([]bool)), 4, t)
+	testSequenceFunction(Filter(arr, isEven), []int{2, 4}, t)
+	testSequenceFunction(Filter(&arr, isEven), []int{2, 4}, t)
+	testSequenceFunction(Filter([0]int{}, isEven), []int{}, t)
+	testSequenceFunction(Filter("HéLlo", isUpper), "HL", t)
+	testSequenceFunction(Reject("HéLlo", isUpper), "élo", t)
+	testSequenceFunction(Reject(testString("Ab"), isUpper), testString("b"), t)
+	testSequenceFunction(Filter("", isUpper), "", t)
+	testSequenceFunction(Foldr("abc", "", func(r rune, s string) string { return s + string(r) }), "abc", t)
+	testSequenceFunction(Foldl("abc", "", func(s string, r rune) string { return s + string(r) }), "cba", t)
+	testSequenceFunction(Foldl(&arr, 0, func(i, j int) int { return i*10 + j }), 4321, t)
+	testSequenceFunction(Find("abCd", isUpper), 'C', t)
+	testSequenceFunction(FindIndex("éC", isUpper), 2, t)
+	testSequenceFunction(FindIndex(arr, isEven), 1, t)
+	testSequenceFunction(Some("abc", isUpper), false, t)
+	testSequenceFunction(Every("ABC", isUpper), true, t)
+	testSequenceFunction(Count(&arr, isEven), 2, t)
+	testSequenceFunction(Max("bca", nil), 'c', t)
+	testSequenceFunction(Min(arr, nil), 1, t)
+	testSequenceFunction(Max([0]int{}, nil), nil, t)
+
+	// Iterators, which should be stopped as
+	// soon as the result is known
+	testIterFunction(func(seq interface{}) interface{} { return Map(seq, isEven) }, []bool{false, true, false, true}, 4, t)
+	testIterFunction(func(seq interface{}) interface{} { return Filter(seq, isEven) }, []int{2, 4}, 4, t)
+	testIterFunction(func(seq interface{}) interface{} { return Reject(seq, isEven) }, []int{1, 3}, 4, t)
+	testIterFunction(func(seq interface{}) interface{} {
+		return Foldl(seq, 0, func(i, j int) int { return i*10 + j })
+	}, 4321, 4, t)
+	testIterFunction(func(seq interface{}) interface{} { return Find(seq, isEven) }, 2, 2, t)
+	testIterFunction(func(seq interface{}) interface{} { return FindIndex(seq, isEven) }, 1, 2, t)
+	testIterFunction(func(seq interface{}) interface{} { return Some(seq, isEven) }, true, 2, t)
+	testIterFunction(func(seq interface{}) interface{} { return Every(seq, isEven) }, false, 1, t)
+	testIterFunction(func(seq interface{}) interface{} { return Count(seq, isEven) }, 2, 4, t)
+	testIterFunction(func(seq interface{}) interface{} { return Max(seq, nil) }, 4, 4, t)
+
+	testMap("abc", nil, isEven, mapErrorPrefix+"expected func(int32) U, got func(int) bool: parameter 0 is int, string element is int32", t)
+	testFilter([2]string{}, nil, isEven, filterErrorPrefix+"expected func(string) bool, got func(int) bool: parameter 0 is int, array element is string", t)
+	testFilter((*[2]int)(nil), nil, isEven, filterSliceError, t)
+}
+
+// testIter returns an iterator function over
+// values, and a pointer to the number of values
+// it has yielded.
+func testIter(values ...int) (func(func(int) bool), *int) {
+	n := new(int)
+	return func(yield func(int) bool) {
+		for _, v := range values {
+			*n++
+			if !yield(v) {
+				return
+			}
+		}
+	}, n
+}
+
+// testIterFunction calls f with an iterator over
+// 1, 2, 3, 4, and checks its result and the number
+// of values yielded before the iterator stopped.
+func testIterFunction(f func(seq interface{}) interface{}, expect interface{}, yielded int, t *testing.T) {
+	seq, n := testIter(1, 2, 3, 4)
+	testSequenceFunction(f(seq), expect, t)
+	if *n != yielded {
+		t.Errorf("Expected %v values to be yielded; got %v", yielded, *n)
+	}
+}
+
+func testSequenceFunction(result, expect interface{}, t *testing.T) {
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("Expected result %#v; got %#v", expect, result)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A signature is the generic type of a function
+// argument (such as func(T) U for Map's pred),
+// with the type parameters which are fixed by
+// the other arguments (such as T) filled in.
+type signature struct {
+	in, out []sigParam
+}
+
+// A sigParam is a parameter or result in a
+// signature: either a concrete type, or a
+// type parameter.
+type sigParam struct {
+	typ reflect.Type
+	// If typ is nil, the type parameter's name.
+	// Otherwise, where typ came from (such as
+	// "slice element"), or "" if it is fixed
+	// by the signature (such as Filter's bool).
+	name string
+}
+
+func concrete(typ reflect.Type, from string) sigParam { return sigParam{typ, from} }
+
+func typeParam(name string) sigParam { return sigParam{nil, name} }
+
+// func(T) U
+func mapSig(elem sigParam) signature {
+	return signature{[]sigParam{elem}, []sigParam{typeParam("U")}}
+}
+
+// func(T) V, where V is Map's result type
+func mapToSig(elem sigParam, result reflect.Type) signature {
+	return signature{[]sigParam{elem}, []sigParam{concrete(result, "")}}
+}
+
+// func(T) bool
+func predicateSig(elem sigParam) signature {
+	return signature{[]sigParam{elem}, []sigParam{concrete(boolType, "")}}
+}
+
+// func(T, T) bool
+func lessSig(elem sigParam) signature {
+	return signature{[]sigParam{elem, elem}, []sigParam{concrete(boolType, "")}}
+}
+
+// func(T, U) U
+func foldrSig(elem, u sigParam) signature {
+	return signature{[]sigParam{elem, u}, []sigParam{u}}
+}
+
+// func(U, T) U
+func foldlSig(elem, u sigParam) signature {
+	return signature{[]sigParam{u, elem}, []sigParam{u}}
+}
+
+// String returns s in Go syntax, such as
+//
+//	func(int, U) U
+func (s signature) String() string {
+	return funcString(s.in, s.out)
+}
+
+func (p sigParam) String() string {
+	if p.typ == nil {
+		return p.name
+	}
+	return p.typ.String()
+}
+
+func funcString(in, out []sigParam) string {
+	str := "func(" + paramsString(in) + ")"
+	switch len(out) {
+	case 0:
+	case 1:
+		str += " " + out[0].String()
+	default:
+		str += " (" + paramsString(out) + ")"
+	}
+	return str
+}
+
+func paramsString(params []sigParam) string {
+	strs := make([]string, len(params))
+	for i, p := range params {
+		strs[i] = p.String()
+	}
+	return strings.Join(strs, ", ")
+}
+
+// check returns a description of the ways in
+// which fType fails to match s, or "" if
+// it matches. Type parameters are bound to the
+// first type at which they appear in fType.
+//
+// Types need not be identical. Values flow into
+// fType's parameters and out of its results, so
+// a concrete type must be assignable to the
+// corresponding parameter, and a result must be
+// assignable to the corresponding concrete
+// type, or to the type to which its type
+// parameter was bound (as when Foldr passes
+// each result back to pred).
+func (s signature) check(fType reflect.Type) string {
+	var reasons []string
+	if fType.NumIn() != len(s.in) {
+		reasons = append(reasons, "has "+count(fType.NumIn(), "parameter")+", want "+strconv.Itoa(len(s.in)))
+	}
+	if fType.NumOut() != len(s.out) {
+		reasons = append(reasons, "has "+count(fType.NumOut(), "result")+", want "+strconv.Itoa(len(s.out)))
+	}
+	if reasons != nil {
+		// Without the right number of parameters
+		// and results, they can't be compared
+		return strings.Join(reasons, "; ")
+	}
+
+	type binding struct {
+		typ   reflect.Type
+		where string
+	}
+	bound := make(map[string]binding)
+	match := func(p sigParam, typ reflect.Type, where string, result bool) {
+		// want is the type from the signature; values
+		// flow from want into a parameter of type typ,
+		// or out of a result of type typ into want.
+		assignable := func(want, typ reflect.Type) bool {
+			if result {
+				return typ.AssignableTo(want)
+			}
+			return want.AssignableTo(typ)
+		}
+		switch b, ok := bound[p.name]; {
+		case p.typ != nil:
+			if !assignable(p.typ, typ) {
+				if p.name == "" {
+					reasons = append(reasons, where+" is "+typ.String()+", want "+p.typ.String())
+				} else {
+					reasons = append(reasons, where+" is "+typ.String()+", "+p.name+" is "+p.typ.String())
+				}
+			}
+		case !ok:
+			bound[p.name] = binding{typ, where}
+		case !assignable(b.typ, typ):
+			reasons = append(reasons, where+" is "+typ.String()+", "+b.where+" is "+b.typ.String())
+		}
+	}
+	for i, p := range s.in {
+		match(p, fType.In(i), "parameter "+strconv.Itoa(i), false)
+	}
+	for i, p := range s.out {
+		where := "result"
+		if len(s.out) > 1 {
+			where += " " + strconv.Itoa(i)
+		}
+		match(p, fType.Out(i), where, true)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func count(n int, noun string) string {
+	if n != 1 {
+		noun += "s"
+	}
+	return strconv.Itoa(n) + " " + noun
+}
@@ -0,0 +1,38 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	stringType := reflect.TypeOf("")
+	u := typeParam("U")
+	ints := concrete(intType, "slice element")
+	strs := concrete(stringType, "slice element")
+	pair := signature{[]sigParam{ints, u}, []sigParam{u, concrete(boolType, "")}}
+
+	testSignature(mapSig(ints), func(int) string { return "" }, "func(int) U", "", t)
+	testSignature(mapSig(ints), func(string) bool { return false }, "func(int) U", "parameter 0 is string, slice element is int", t)
+	testSignature(predicateSig(strs), func() {}, "func(string) bool", "has 0 parameters, want 1; has 0 results, want 1", t)
+	testSignature(lessSig(ints), func(int) bool { return false }, "func(int, int) bool", "has 1 parameter, want 2", t)
+	testSignature(foldrSig(ints, u), func(int, string) string { return "" }, "func(int, U) U", "", t)
+	testSignature(foldlSig(ints, concrete(stringType, "zero")), func(int, int) int { return 0 }, "func(string, int) string",
+		"parameter 0 is int, zero is string; result is int, zero is string", t)
+	testSignature(pair, func(int, string) (string, bool) { return "", false }, "func(int, U) (U, bool)", "", t)
+	testSignature(pair, func(int, string) (int, int) { return 0, 0 }, "func(int, U) (U, bool)",
+		"result 0 is int, parameter 1 is string; result 1 is int, want bool", t)
+}
+
+func testSignature(sig signature, f interface{}, str, reason string, t *testing.T) {
+	if sig.String() != str {
+		t.Errorf("Expected signature %v; got %v", str, sig)
+	}
+	if r := sig.check(reflect.TypeOf(f)); r != reason {
+		t.Errorf("Expected reason %q; got %q", reason, r)
+	}
+}
@@ -0,0 +1,571 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/joshlf13/illegal"
+)
+
+// A Pipeline is a chain of stages over the elements
+// of a sequence, created by Stream, such as
+//
+//	n := Stream(slc).Filter(isEven).Map(square).Take(3).Count()
+//
+// Each stage's function is checked against the element
+// type produced by the stages before it as the stage is
+// added, so the whole chain is checked before any of it
+// runs. Nothing runs until one of the terminal methods
+// (Collect, Count, Find, Reduce, Some, Every, Max, and
+// Min) is called, and then the stages are applied to
+// each element in turn in a single pass over the
+// sequence, without building any intermediate slices.
+// As with Find, Some, and Every, the pass stops as
+// soon as the result is known, or once every Take
+// stage has passed as many elements as it allows.
+//
+// The stage methods do not panic; instead, a Pipeline
+// records the *Error for the first stage whose argument
+// was mismatched (see its Stage field), which Err
+// returns, and which the terminal methods panic with
+// (or, for their E variants, return). Each method
+// returns a new Pipeline, leaving p unchanged, so a
+// Pipeline may be extended in more than one way, or
+// run more than once.
+type Pipeline struct {
+	slc    interface{}
+	seq    sequence
+	stages []stage
+	// The element type which the last stage produces
+	elem sigParam
+	// Whether any stage is a Map, in which
+	// case elem may differ from seq.elem
+	mapped bool
+	err    *Error
+}
+
+type stageKind int
+
+const (
+	filterStage stageKind = iota
+	rejectStage
+	mapStage
+	takeStage
+	skipStage
+)
+
+type stage struct {
+	kind stageKind
+	// For filterStage, rejectStage, and mapStage
+	f reflect.Value
+	// For takeStage and skipStage
+	n int
+}
+
+//	func Stream(slc []T) *Pipeline[T]
+//
+// Stream returns a Pipeline with no stages over
+// the elements of slc.
+func Stream(slc interface{}) *Pipeline {
+	seq, ok := newSequence(slc)
+	if !ok {
+		return &Pipeline{slc: slc, err: newError("Stream", NotSlice, slc, nil, nil)}
+	}
+	return &Pipeline{slc: slc, seq: seq, elem: seq.elemParam()}
+}
+
+// Err returns the *Error for the first stage of
+// p whose argument was mismatched, or nil if
+// there is none.
+func (p *Pipeline) Err() error {
+	if p.err == nil {
+		return nil
+	}
+	return p.err
+}
+
+//	func (p *Pipeline[T]) Filter(pred func(T) bool) *Pipeline[T]
+//
+// Filter adds a stage which passes on those
+// elements for which pred returns true.
+func (p *Pipeline) Filter(pred interface{}) *Pipeline {
+	f, err := p.check("Filter", pred, nil, predicateSig(p.elem))
+	return p.with(stage{kind: filterStage, f: f}, p.elem, err)
+}
+
+//	func (p *Pipeline[T]) Reject(pred func(T) bool) *Pipeline[T]
+//
+// Reject adds a stage which passes on those
+// elements for which pred returns false.
+func (p *Pipeline) Reject(pred interface{}) *Pipeline {
+	f, err := p.check("Reject", pred, nil, predicateSig(p.elem))
+	return p.with(stage{kind: rejectStage, f: f}, p.elem, err)
+}
+
+//	func (p *Pipeline[T]) Map(pred func(T) U) *Pipeline[U]
+//
+// Map adds a stage which passes on the result
+// of applying pred to each element.
+func (p *Pipeline) Map(pred interface{}) *Pipeline {
+	f, err := p.check("Map", pred, nil, mapSig(p.elem))
+	if err != nil {
+		return p.with(stage{}, p.elem, err)
+	}
+	q := p.with(stage{kind: mapStage, f: f}, p.stageResult(f.Type().Out(0)), nil)
+	q.mapped = true
+	return q
+}
+
+//	func (p *Pipeline[T]) Take(n int) *Pipeline[T]
+//
+// Take adds a stage which passes on the first
+// n elements which reach it, and then stops
+// the pipeline. If n is negative, it is
+// treated as 0.
+func (p *Pipeline) Take(n int) *Pipeline {
+	if n < 0 {
+		n = 0
+	}
+	return p.with(stage{kind: takeStage, n: n}, p.elem, nil)
+}
+
+//	func (p *Pipeline[T]) Skip(n int) *Pipeline[T]
+//
+// Skip adds a stage which discards the first
+// n elements which reach it, and passes on
+// the rest. If n is negative, it is treated
+// as 0.
+func (p *Pipeline) Skip(n int) *Pipeline {
+	if n < 0 {
+		n = 0
+	}
+	return p.with(stage{kind: skipStage, n: n}, p.elem, nil)
+}
+
+//	func (p *Pipeline[T]) Collect() []T
+//
+// Collect returns the elements which reach the
+// end of p as a slice. If p has no Map stages
+// and its sequence is a slice, the result has
+// the same type as that slice, as with Filter;
+// otherwise, it is a []T.
+func (p *Pipeline) Collect() interface{} {
+	ret, err := p.CollectE()
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) CollectE() ([]T, error)
+//
+// CollectE is like Collect, except that it returns
+// an *Error rather than panicking if the types of
+// the arguments to p's stages do not match.
+func (p *Pipeline) CollectE() (interface{}, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	var ret reflect.Value
+	if p.mapped {
+		ret = reflect.MakeSlice(reflect.SliceOf(p.elem.typ), 0, 0)
+	} else {
+		ret = p.seq.subset()
+	}
+
+	p.run(func(v reflect.Value) bool {
+		ret = reflect.Append(ret, v)
+		return true
+	})
+
+	return ret.Interface(), nil
+}
+
+//	func (p *Pipeline[T]) Count() int
+//
+// Count returns the number of elements which
+// reach the end of p.
+func (p *Pipeline) Count() int {
+	ret, err := p.CountE()
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) CountE() (int, error)
+//
+// CountE is like Count, except that it returns
+// an *Error rather than panicking if the types
+// of the arguments to p's stages do not match.
+func (p *Pipeline) CountE() (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	ret := 0
+	p.run(func(v reflect.Value) bool {
+		ret++
+		return true
+	})
+
+	return ret, nil
+}
+
+//	func (p *Pipeline[T]) Find(pred func(T) bool) T
+//
+// Find returns the first element which reaches
+// the end of p for which pred returns true, or
+// a nil interface if there is none, as the
+// package-level Find does.
+func (p *Pipeline) Find(pred interface{}) interface{} {
+	ret, err := p.FindE(pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) FindE(pred func(T) bool) (T, error)
+//
+// FindE is like Find, except that it returns an
+// *Error rather than panicking if the types of
+// pred or the arguments to p's stages do not
+// match.
+func (p *Pipeline) FindE(pred interface{}) (interface{}, error) {
+	f, err := p.check("Find", pred, nil, predicateSig(p.elem))
+	if err != nil {
+		return nil, err
+	}
+
+	var ret interface{}
+	args := make([]reflect.Value, 1)
+	p.run(func(v reflect.Value) bool {
+		args[0] = v
+		if f.Call(args)[0].Bool() {
+			ret = v.Interface()
+			return false
+		}
+		return true
+	})
+
+	return ret, nil
+}
+
+//	func (p *Pipeline[T]) Reduce(zero U, pred func(U, T) U) U
+//
+// Reduce applies pred to each element which
+// reaches the end of p, in order, using the
+// previous call's return value as its first
+// argument, and zero for the first call. There
+// is no Foldl or Foldr method, since a Pipeline
+// makes only one pass over its sequence, while
+// the package-level Foldl visits slc from the end,
+// and Foldr passes pred its arguments the other
+// way around.
+func (p *Pipeline) Reduce(zero, pred interface{}) interface{} {
+	ret, err := p.ReduceE(zero, pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) ReduceE(zero U, pred func(U, T) U) (U, error)
+//
+// ReduceE is like Reduce, except that it returns
+// an *Error rather than panicking if the types of
+// zero, pred, or the arguments to p's stages do
+// not match.
+func (p *Pipeline) ReduceE(zero, pred interface{}) (interface{}, error) {
+	sig := foldlSig(p.elem, typeParam("U"))
+	f, err := p.check("Reduce", pred, zero, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	z, reason := zeroValue(reflect.ValueOf(zero), f.Type().In(0), "parameter 0")
+	if reason != "" {
+		if z.IsValid() {
+			sig = foldlSig(p.elem, concrete(z.Type(), "zero"))
+		}
+		return nil, p.stageError("Reduce", ZeroMismatch, pred, zero).because(sig, reason)
+	}
+
+	args := make([]reflect.Value, 2)
+	args[0] = z
+	p.run(func(v reflect.Value) bool {
+		args[1] = v
+		args[0] = f.Call(args)[0]
+		return true
+	})
+
+	return args[0].Interface(), nil
+}
+
+//	func (p *Pipeline[T]) Some(pred func(T) bool) bool
+//
+// Some returns whether pred returns true for
+// any element which reaches the end of p.
+func (p *Pipeline) Some(pred interface{}) bool {
+	ret, err := p.SomeE(pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) SomeE(pred func(T) bool) (bool, error)
+//
+// SomeE is like Some, except that it returns an
+// *Error rather than panicking if the types of
+// pred or the arguments to p's stages do not
+// match.
+func (p *Pipeline) SomeE(pred interface{}) (bool, error) {
+	f, err := p.check("Some", pred, nil, predicateSig(p.elem))
+	if err != nil {
+		return false, err
+	}
+
+	ret := false
+	args := make([]reflect.Value, 1)
+	p.run(func(v reflect.Value) bool {
+		args[0] = v
+		ret = f.Call(args)[0].Bool()
+		return !ret
+	})
+
+	return ret, nil
+}
+
+//	func (p *Pipeline[T]) Every(pred func(T) bool) bool
+//
+// Every returns whether pred returns true for
+// every element which reaches the end of p.
+func (p *Pipeline) Every(pred interface{}) bool {
+	ret, err := p.EveryE(pred)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) EveryE(pred func(T) bool) (bool, error)
+//
+// EveryE is like Every, except that it returns an
+// *Error rather than panicking if the types of
+// pred or the arguments to p's stages do not
+// match.
+func (p *Pipeline) EveryE(pred interface{}) (bool, error) {
+	f, err := p.check("Every", pred, nil, predicateSig(p.elem))
+	if err != nil {
+		return false, err
+	}
+
+	ret := true
+	args := make([]reflect.Value, 1)
+	p.run(func(v reflect.Value) bool {
+		args[0] = v
+		ret = f.Call(args)[0].Bool()
+		return ret
+	})
+
+	return ret, nil
+}
+
+//	func (p *Pipeline[T]) Max(less func(T, T) bool) T
+//
+// Max returns the maximum element which reaches
+// the end of p according to less, or according to
+// the natural order of T if less is nil, as the
+// package-level Max does.
+func (p *Pipeline) Max(less interface{}) interface{} {
+	ret, err := p.MaxE(less)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) MaxE(less func(T, T) bool) (T, error)
+//
+// MaxE is like Max, except that it returns an
+// *Error rather than panicking if the types of
+// less or the arguments to p's stages do not
+// match.
+func (p *Pipeline) MaxE(less interface{}) (interface{}, error) {
+	return p.extreme("Max", less, true)
+}
+
+//	func (p *Pipeline[T]) Min(less func(T, T) bool) T
+//
+// Min returns the minimum element which reaches
+// the end of p according to less, or according to
+// the natural order of T if less is nil, as the
+// package-level Min does.
+func (p *Pipeline) Min(less interface{}) interface{} {
+	ret, err := p.MinE(less)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+//	func (p *Pipeline[T]) MinE(less func(T, T) bool) (T, error)
+//
+// MinE is like Min, except that it returns an
+// *Error rather than panicking if the types of
+// less or the arguments to p's stages do not
+// match.
+func (p *Pipeline) MinE(less interface{}) (interface{}, error) {
+	return p.extreme("Min", less, false)
+}
+
+// extreme implements Max (if max is true) and Min.
+func (p *Pipeline) extreme(fname string, less interface{}, max bool) (interface{}, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if less == nil {
+		if !illegal.Ordered(p.elem.typ) {
+			return nil, p.stageError(fname, NotOrdered, less, nil)
+		}
+		less = naturalLess(p.elem.typ).Interface()
+	}
+
+	f, err := p.check(fname, less, nil, lessSig(p.elem))
+	if err != nil {
+		return nil, err
+	}
+
+	// args[0] is the best element so far, and
+	// args[1] the next, for Max, and the other
+	// way around for Min
+	best, next := 0, 1
+	if !max {
+		best, next = 1, 0
+	}
+	args := make([]reflect.Value, 2)
+	p.run(func(v reflect.Value) bool {
+		if !args[best].IsValid() {
+			args[best] = v
+			return true
+		}
+		args[next] = v
+		if f.Call(args)[0].Bool() {
+			args[best] = args[next]
+		}
+		return true
+	})
+
+	if !args[best].IsValid() {
+		return nil, nil
+	}
+	return args[best].Interface(), nil
+}
+
+// check checks pred, the argument to the stage
+// added by the method fname (which may be a
+// terminal method), against sig, and returns
+// it as a reflect.Value. If p already has an
+// error, check returns that instead.
+func (p *Pipeline) check(fname string, pred, zero interface{}, sig signature) (reflect.Value, *Error) {
+	if p.err != nil {
+		return reflect.Value{}, p.err
+	}
+
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func {
+		return f, p.stageError(fname, NotFunction, pred, zero)
+	}
+
+	if reason := sig.check(f.Type()); reason != "" {
+		return f, p.stageError(fname, SignatureMismatch, pred, zero).because(sig, reason)
+	}
+	return f, nil
+}
+
+// stageError returns an *Error of the given kind
+// for the stage which the method fname would add
+// to p.
+func (p *Pipeline) stageError(fname string, kind ErrorKind, pred, zero interface{}) *Error {
+	return newError("Stream", kind, p.slc, pred, zero).atStage(len(p.stages)+1, fname)
+}
+
+// stageResult describes typ, the result type of
+// the Map stage which would be added to p, for
+// use in the signatures of later stages.
+func (p *Pipeline) stageResult(typ reflect.Type) sigParam {
+	return concrete(typ, "stage "+strconv.Itoa(len(p.stages)+1)+" result")
+}
+
+// with returns a copy of p with s added as its
+// last stage, producing elements described by
+// elem, or, if err is not nil, with err as its
+// error instead. If p already has an error,
+// with returns a copy of p unchanged.
+func (p *Pipeline) with(s stage, elem sigParam, err *Error) *Pipeline {
+	q := *p
+	switch {
+	case p.err != nil:
+	case err != nil:
+		q.err = err
+	default:
+		// Never append in place, since p's
+		// stages may be shared with others
+		q.stages = append(p.stages[:len(p.stages):len(p.stages)], s)
+		q.elem = elem
+	}
+	return &q
+}
+
+// run applies p's stages to each element of its
+// sequence in turn, and calls f with each element
+// which reaches the end, until f returns false or
+// a Take stage has passed as many elements as it
+// allows.
+func (p *Pipeline) run(f func(v reflect.Value) bool) {
+	for _, s := range p.stages {
+		if s.kind == takeStage && s.n == 0 {
+			return
+		}
+	}
+
+	// The number of elements which each Take
+	// stage has passed on, or each Skip stage
+	// has discarded
+	counts := make([]int, len(p.stages))
+	args := make([]reflect.Value, 1)
+	p.seq.each(func(i int, v reflect.Value) bool {
+		// Whether a Take stage has reached its limit,
+		// in which case this is the last element
+		// which may be passed on
+		done := false
+		for j, s := range p.stages {
+			switch s.kind {
+			case filterStage, rejectStage:
+				args[0] = v
+				if s.f.Call(args)[0].Bool() != (s.kind == filterStage) {
+					return !done
+				}
+			case mapStage:
+				args[0] = v
+				v = s.f.Call(args)[0]
+			case takeStage:
+				counts[j]++
+				done = done || counts[j] == s.n
+			case skipStage:
+				if counts[j] < s.n {
+					counts[j]++
+					return !done
+				}
+			}
+		}
+		return f(v) && !done
+	})
+}
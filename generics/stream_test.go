@@ -0,0 +1,149 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generics
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	type ints []int
+	slc := ints{1, 2, 3, 4, 5, 6}
+	isEven := func(i int) bool { return i%2 == 0 }
+	square := func(i int) int { return i * i }
+	add := func(acc, i int) int { return acc*10 + i }
+
+	// Stream should succeed
+	testMapFunc(func() interface{} { return Stream(slc).Collect() }, ints{1, 2, 3, 4, 5, 6}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Filter(isEven).Collect() }, ints{2, 4, 6}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Reject(isEven).Map(square).Collect() }, []int{1, 9, 25}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Map(strconv.Itoa).Collect() }, []string{"1", "2", "3", "4", "5", "6"}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Skip(1).Take(3).Collect() }, ints{2, 3, 4}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Take(3).Skip(1).Collect() }, ints{2, 3}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Filter(isEven).Take(2).Skip(-1).Collect() }, ints{2, 4}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Take(10).Collect() }, ints{1, 2, 3, 4, 5, 6}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Take(-1).Collect() }, ints{}, nil, t)
+	testMapFunc(func() interface{} { return Stream("héllo").Filter(func(r rune) bool { return r != 'l' }).Collect() }, []rune{'h', 'é', 'o'}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Filter(isEven).Map(square).Count() }, 3, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Map(square).Find(func(i int) bool { return i > 10 }) }, 16, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Take(2).Find(func(i int) bool { return i > 2 }) }, nil, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Filter(isEven).Reduce(0, add) }, 246, nil, t)
+	testMapFunc(func() interface{} {
+		return Stream(slc).Skip(5).Reduce(nil, func(acc []int, i int) []int { return append(acc, i) })
+	}, []int{6}, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Filter(isEven).Every(isEven) }, true, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Reject(isEven).Some(isEven) }, false, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Map(func(i int) int { return -i }).Max(nil) }, -1, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Map(strconv.Itoa).Min(nil) }, "1", nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Max(func(i, j int) bool { return i%4 < j%4 }) }, 3, nil, t)
+	testMapFunc(func() interface{} { return Stream(slc).Take(0).Min(nil) }, nil, nil, t)
+
+	// Stream should panic
+	testMapFunc(func() interface{} { return Stream(3).Count() }, nil, streamSliceError, t)
+	testMapFunc(func() interface{} { return Stream(slc).Filter(isEven).Map(3).Count() }, nil,
+		streamErrorPrefix+"stage 2 (Map): "+functionError, t)
+	testMapFunc(func() interface{} { return Stream(slc).Map(strconv.Itoa).Filter(isEven).Count() }, nil,
+		streamErrorPrefix+"stage 2 (Filter): expected func(string) bool, got func(int) bool: parameter 0 is int, stage 1 result is string", t)
+	testMapFunc(func() interface{} { return Stream(slc).Take(1).Reject(square).Collect() }, nil,
+		streamErrorPrefix+"stage 2 (Reject): expected func(int) bool, got func(int) int: result is int, want bool", t)
+	testMapFunc(func() interface{} { return Stream(slc).Map(strconv.Itoa).Some(isEven) }, nil,
+		streamErrorPrefix+"stage 2 (Some): expected func(string) bool, got func(int) bool: parameter 0 is int, stage 1 result is string", t)
+	testMapFunc(func() interface{} { return Stream(slc).Reduce("", add) }, nil,
+		streamErrorPrefix+"stage 1 (Reduce): expected func(string, int) string, got func(int, int) int: zero is string, parameter 0 is int", t)
+	testMapFunc(func() interface{} { return Stream(slc).Map(func(i int) []int { return nil }).Max(nil) }, nil,
+		streamErrorPrefix+"stage 2 (Max): "+orderError, t)
+	testMapFunc(func() interface{} { return Stream(slc).Min(square) }, nil,
+		streamErrorPrefix+"stage 1 (Min): expected func(int, int) bool, got func(int) int: has 1 parameter, want 2", t)
+}
+
+func TestStreamFused(t *testing.T) {
+	seq, n := testIter(1, 2, 3, 4, 5, 6)
+	var calls []string
+	isEven := func(i int) bool {
+		calls = append(calls, "isEven("+strconv.Itoa(i)+")")
+		return i%2 == 0
+	}
+	square := func(i int) int {
+		calls = append(calls, "square("+strconv.Itoa(i)+")")
+		return i * i
+	}
+
+	// The stages should be applied to each element
+	// in turn, and the sequence stopped as soon as
+	// Take has passed on enough elements
+	ret := Stream(seq).Filter(isEven).Map(square).Take(2).Collect()
+	testSequenceFunction(ret, []int{4, 16}, t)
+	testSequenceFunction(calls, []string{"isEven(1)", "isEven(2)", "square(2)", "isEven(3)", "isEven(4)", "square(4)"}, t)
+	if *n != 4 {
+		t.Errorf("Expected 4 values to be yielded; got %v", *n)
+	}
+
+	// Nothing should run until a terminal method
+	// is called, or at all for Take(0)
+	calls, *n = nil, 0
+	p := Stream(seq).Map(square)
+	p.Take(0).Count()
+	if calls != nil || *n != 0 {
+		t.Errorf("Expected no calls; got %v after %v values", calls, *n)
+	}
+
+	// Terminal methods should stop early too
+	if !p.Some(func(i int) bool { return i > 5 }) || *n != 3 {
+		t.Errorf("Expected Some to stop after 3 values; got %v", *n)
+	}
+}
+
+func TestStreamReuse(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+	p := Stream([]int{1, 2, 3, 4}).Filter(isEven)
+
+	// Adding stages should not change p, even
+	// when its stages have spare capacity
+	q := p.Skip(0).Skip(0)
+	a := q.Map(strconv.Itoa)
+	b := q.Take(1)
+	testSequenceFunction(p.Collect(), []int{2, 4}, t)
+	testSequenceFunction(a.Collect(), []string{"2", "4"}, t)
+	testSequenceFunction(b.Collect(), []int{2}, t)
+	testSequenceFunction(b.Collect(), []int{2}, t)
+
+	// The first error should be kept
+	bad := p.Map(3).Filter(nil)
+	if err := bad.Err(); err == nil || err.(*Error).Stage != 2 || err.(*Error).StageFunc != "Map" {
+		t.Errorf("Expected an error for stage 2 (Map); got %v", err)
+	}
+	if err := p.Err(); err != nil {
+		t.Errorf("Expected no error; got %v", err)
+	}
+}
+
+func TestStreamErrorVariants(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+	less := func(i, j int) bool { return i < j }
+	p := Stream([]int{1, 2, 3})
+
+	testErrorVariant(func() (interface{}, error) { return p.CollectE() }, []int{1, 2, 3}, 0, t)
+	testErrorVariant(func() (interface{}, error) { return Stream(nil).CollectE() }, nil, NotSlice, t)
+	testErrorVariant(func() (interface{}, error) { return p.CountE() }, 3, 0, t)
+	testErrorVariant(func() (interface{}, error) { return p.Map(less).CountE() }, 0, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return p.FindE(isEven) }, 2, 0, t)
+	testErrorVariant(func() (interface{}, error) { return p.FindE(less) }, nil, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return p.ReduceE(0, func(i, j int) int { return i + j }) }, 6, 0, t)
+	testErrorVariant(func() (interface{}, error) { return p.ReduceE(nil, func(i, j int) int { return i + j }) }, nil, ZeroMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return p.SomeE(isEven) }, true, 0, t)
+	testErrorVariant(func() (interface{}, error) { return p.SomeE(3) }, false, NotFunction, t)
+	testErrorVariant(func() (interface{}, error) { return p.EveryE(isEven) }, false, 0, t)
+	testErrorVariant(func() (interface{}, error) { return p.Reject(3).EveryE(isEven) }, false, NotFunction, t)
+	testErrorVariant(func() (interface{}, error) { return p.MaxE(less) }, 3, 0, t)
+	testErrorVariant(func() (interface{}, error) { return p.MinE(isEven) }, nil, SignatureMismatch, t)
+	testErrorVariant(func() (interface{}, error) { return Stream([]bool{}).MinE(nil) }, nil, NotOrdered, t)
+
+	_, err := p.Filter(isEven).Take(1).Map(isEven).Filter(less).CollectE()
+	if e, ok := err.(*Error); !ok || e.Stage != 4 || e.StageFunc != "Filter" || e.Pred != reflect.TypeOf(less) {
+		t.Errorf("Expected an *Error for stage 4 (Filter); got %#v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package typed implements the functions of package
+// generics using type parameters, so that their
+// signatures are checked at compile time.
+//
+// Each function has the same semantics as its
+// counterpart in package generics, except where
+// that function returns a nil interface to signal
+// that there is no result (as Find, Max, and Min
+// do); there, the typed function instead returns
+// the zero value and false. This allows code to be
+// migrated from package generics one call at a time.
+package typed
+
+import (
+	"reflect"
+
+	"github.com/joshlf13/illegal"
+)
+
+// Identity returns its argument.
+func Identity[T any](x T) T { return x }
+
+// Map applies pred to each element of slc
+// successively, and returns the results.
+// The returned slice has the same capacity
+// as slc.
+func Map[T, U any](slc []T, pred func(T) U) []U {
+	ret := make([]U, len(slc), cap(slc))
+	for i, t := range slc {
+		ret[i] = pred(t)
+	}
+	return ret
+}
+
+// Filter applies pred to each element of slc,
+// and returns those elements for which pred
+// returned true. The returned slice will be
+// only as long as it needs to to store all
+// "true" elements, not necessarily as long
+// as slc.
+func Filter[S ~[]T, T any](slc S, pred func(T) bool) S {
+	ret := S{}
+	for _, t := range slc {
+		if pred(t) {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+// Reject applies pred to each element of slc,
+// and returns those elements for which pred
+// returned false. The returned slice will be
+// only as long as it needs to to store all
+// "false" elements, not necessarily as long
+// as slc.
+func Reject[S ~[]T, T any](slc S, pred func(T) bool) S {
+	ret := S{}
+	for _, t := range slc {
+		if !pred(t) {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+// Foldr applies pred to each element of slc, using
+// the previous call's return value as its second
+// argument. In other words, it does:
+//
+//	tmp := pred(slc[0], zero)
+//	tmp = pred(slc[1], tmp)
+//	tmp = pred(slc[2], tmp)
+//	...
+//	return tmp
+func Foldr[T, U any](slc []T, zero U, pred func(T, U) U) U {
+	for _, t := range slc {
+		zero = pred(t, zero)
+	}
+	return zero
+}
+
+// Foldl applies pred to each element of slc in reverse
+// order, using the previous call's return value as its
+// first argument. In other words, it does:
+//
+//	tmp := pred(zero, slc[len(slc)-1])
+//	tmp = pred(tmp, slc[len(slc)-2])
+//	tmp = pred(tmp, slc[len(slc)-3])
+//	...
+//	return tmp
+func Foldl[T, U any](slc []T, zero U, pred func(U, T) U) U {
+	for i := len(slc) - 1; i > -1; i-- {
+		zero = pred(zero, slc[i])
+	}
+	return zero
+}
+
+// Find applies pred to each element in slc,
+// returning the first element for which pred
+// returns true. If pred never returns true,
+// Find returns the zero value and false.
+func Find[T any](slc []T, pred func(T) bool) (T, bool) {
+	for _, t := range slc {
+		if pred(t) {
+			return t, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// FindIndex applies pred to each element in slc,
+// returning the index of the first element for which
+// pred returns true. If pred never returns true,
+// FindIndex returns -1.
+func FindIndex[T any](slc []T, pred func(T) bool) int {
+	for i, t := range slc {
+		if pred(t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Some applies pred to each element in slc.
+// If any of those calls returns true, Some
+// returns true. Otherwise, it returns false.
+func Some[T any](slc []T, pred func(T) bool) bool {
+	for _, t := range slc {
+		if pred(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Every applies pred to each element in slc.
+// If any of those calls returns false, Every
+// returns false. Otherwise, it returns true.
+func Every[T any](slc []T, pred func(T) bool) bool {
+	for _, t := range slc {
+		if !pred(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Count applies pred to each element in slc,
+// and returns the number of elements for which
+// the call returned true.
+func Count[T any](slc []T, pred func(T) bool) int {
+	ret := 0
+	for _, t := range slc {
+		if pred(t) {
+			ret++
+		}
+	}
+	return ret
+}
+
+// Max finds the largest element in slc according
+// to less (less(a, b) returns (a < b)). Of several
+// largest elements, the first is returned.
+// If len(slc) == 0, Max returns the zero value
+// and false.
+//
+// If less is nil, Max uses the natural order
+// defined by illegal.Compare, and panics if T
+// is not ordered.
+func Max[T any](slc []T, less func(T, T) bool) (T, bool) {
+	if less == nil {
+		less = naturalLess[T]("Max")
+	}
+	var max T
+	if len(slc) == 0 {
+		return max, false
+	}
+	max = slc[0]
+	for _, t := range slc[1:] {
+		if less(max, t) {
+			max = t
+		}
+	}
+	return max, true
+}
+
+// Min finds the smallest element in slc according
+// to less (less(a, b) returns (a < b)). Of several
+// smallest elements, the first is returned.
+// If len(slc) == 0, Min returns the zero value
+// and false.
+//
+// If less is nil, Min uses the natural order
+// defined by illegal.Compare, and panics if T
+// is not ordered.
+func Min[T any](slc []T, less func(T, T) bool) (T, bool) {
+	if less == nil {
+		less = naturalLess[T]("Min")
+	}
+	var min T
+	if len(slc) == 0 {
+		return min, false
+	}
+	min = slc[0]
+	for _, t := range slc[1:] {
+		if less(t, min) {
+			min = t
+		}
+	}
+	return min, true
+}
+
+func naturalLess[T any](fname string) func(T, T) bool {
+	var zero T
+	typ := reflect.TypeOf(&zero).Elem()
+	if !illegal.Ordered(typ) {
+		panic("typed." + fname + ": element type is not ordered")
+	}
+	compare := illegal.CompareFunc(typ)
+	return func(a, b T) bool { return compare(reflect.ValueOf(a), reflect.ValueOf(b)) < 0 }
+}
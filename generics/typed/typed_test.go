@@ -0,0 +1,181 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typed
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/joshlf13/illegal/generics"
+)
+
+type intSlice []int
+
+// Each case calls a function from package generics
+// and its counterpart from this package with the
+// same arguments; the two must return the same
+// results. Functions which signal "no result"
+// differently (Find, Max, and Min) are adapted
+// to the convention of package generics.
+var agreementTests = []struct {
+	name     string
+	generics func() interface{}
+	typed    func() interface{}
+}{
+	{"Identity",
+		func() interface{} { return generics.Identity(3) },
+		func() interface{} { return Identity(3) }},
+
+	{"Map",
+		func() interface{} { return generics.Map([]int{1, 2, 3}, func(i int) int { return i * i }) },
+		func() interface{} { return Map([]int{1, 2, 3}, func(i int) int { return i * i }) }},
+	{"Map/Empty",
+		func() interface{} { return generics.Map([]int{}, strconv.Itoa) },
+		func() interface{} { return Map([]int{}, strconv.Itoa) }},
+	{"Map/Cap",
+		func() interface{} { return cap(generics.Map(make([]int, 1, 5), strconv.Itoa).([]string)) },
+		func() interface{} { return cap(Map(make([]int, 1, 5), strconv.Itoa)) }},
+
+	{"Filter",
+		func() interface{} { return generics.Filter([]int{1, 2, 3, 4}, isEven) },
+		func() interface{} { return Filter([]int{1, 2, 3, 4}, isEven) }},
+	{"Filter/None",
+		func() interface{} { return generics.Filter([]int{1, 3}, isEven) },
+		func() interface{} { return Filter([]int{1, 3}, isEven) }},
+	{"Filter/Named",
+		func() interface{} { return generics.Filter(intSlice{1, 2}, isEven) },
+		func() interface{} { return Filter(intSlice{1, 2}, isEven) }},
+
+	{"Reject",
+		func() interface{} { return generics.Reject([]int{1, 2, 3, 4}, isEven) },
+		func() interface{} { return Reject([]int{1, 2, 3, 4}, isEven) }},
+	{"Reject/None",
+		func() interface{} { return generics.Reject([]int{2, 4}, isEven) },
+		func() interface{} { return Reject([]int{2, 4}, isEven) }},
+
+	{"Foldr",
+		func() interface{} { return generics.Foldr([]int{1, 2, 3}, "", foldrConcat) },
+		func() interface{} { return Foldr([]int{1, 2, 3}, "", foldrConcat) }},
+	{"Foldl",
+		func() interface{} { return generics.Foldl([]int{1, 2, 3}, "", foldlConcat) },
+		func() interface{} { return Foldl([]int{1, 2, 3}, "", foldlConcat) }},
+	{"Foldl/Empty",
+		func() interface{} { return generics.Foldl([]int{}, "zero", foldlConcat) },
+		func() interface{} { return Foldl([]int{}, "zero", foldlConcat) }},
+
+	{"Find",
+		func() interface{} { return generics.Find([]int{1, 2, 3, 4}, isEven) },
+		func() interface{} { return optional(Find([]int{1, 2, 3, 4}, isEven)) }},
+	{"Find/None",
+		func() interface{} { return generics.Find([]int{1, 3}, isEven) },
+		func() interface{} { return optional(Find([]int{1, 3}, isEven)) }},
+
+	{"FindIndex",
+		func() interface{} { return generics.FindIndex([]int{1, 2, 3, 4}, isEven) },
+		func() interface{} { return FindIndex([]int{1, 2, 3, 4}, isEven) }},
+	{"FindIndex/None",
+		func() interface{} { return generics.FindIndex([]int{1, 3}, isEven) },
+		func() interface{} { return FindIndex([]int{1, 3}, isEven) }},
+
+	{"Some",
+		func() interface{} { return generics.Some([]int{1, 2}, isEven) },
+		func() interface{} { return Some([]int{1, 2}, isEven) }},
+	{"Some/Empty",
+		func() interface{} { return generics.Some([]int{}, isEven) },
+		func() interface{} { return Some([]int{}, isEven) }},
+
+	{"Every",
+		func() interface{} { return generics.Every([]int{1, 2}, isEven) },
+		func() interface{} { return Every([]int{1, 2}, isEven) }},
+	{"Every/Empty",
+		func() interface{} { return generics.Every([]int{}, isEven) },
+		func() interface{} { return Every([]int{}, isEven) }},
+
+	{"Count",
+		func() interface{} { return generics.Count([]int{1, 2, 3, 4}, isEven) },
+		func() interface{} { return Count([]int{1, 2, 3, 4}, isEven) }},
+
+	{"Max",
+		func() interface{} { return generics.Max([]int{1, 3, 2}, intLess) },
+		func() interface{} { return optional(Max([]int{1, 3, 2}, intLess)) }},
+	{"Max/Ties",
+		func() interface{} { return generics.Max(pairs(), pairLess) },
+		func() interface{} { return optional(Max(pairs(), pairLess)) }},
+	{"Max/Always",
+		func() interface{} { return generics.Max([]int{1, 3, 2}, always) },
+		func() interface{} { return optional(Max([]int{1, 3, 2}, always)) }},
+	{"Max/Natural",
+		func() interface{} { return generics.Max([]string{"b", "c", "a"}, nil) },
+		func() interface{} { return optional(Max([]string{"b", "c", "a"}, nil)) }},
+	{"Max/Empty",
+		func() interface{} { return generics.Max([]int{}, intLess) },
+		func() interface{} { return optional(Max([]int{}, intLess)) }},
+
+	{"Min",
+		func() interface{} { return generics.Min([]int{2, 1, 3}, intLess) },
+		func() interface{} { return optional(Min([]int{2, 1, 3}, intLess)) }},
+	{"Min/Ties",
+		func() interface{} { return generics.Min(pairs(), pairLess) },
+		func() interface{} { return optional(Min(pairs(), pairLess)) }},
+	{"Min/Always",
+		func() interface{} { return generics.Min([]int{1, 3, 2}, always) },
+		func() interface{} { return optional(Min([]int{1, 3, 2}, always)) }},
+	{"Min/Natural",
+		func() interface{} { return generics.Min([]int{2, 1, 3}, nil) },
+		func() interface{} { return optional(Min([]int{2, 1, 3}, nil)) }},
+	{"Min/Empty",
+		func() interface{} { return generics.Min([]int{}, intLess) },
+		func() interface{} { return optional(Min([]int{}, intLess)) }},
+}
+
+func TestAgreement(t *testing.T) {
+	for _, test := range agreementTests {
+		g, ty := test.generics(), test.typed()
+		if !reflect.DeepEqual(g, ty) {
+			t.Errorf("%s: generics returned %#v; typed returned %#v", test.name, g, ty)
+		}
+	}
+}
+
+func TestNaturalOrderPanics(t *testing.T) {
+	testPanic(func() { Max([]bool{true}, nil) }, "typed.Max: element type is not ordered", t)
+	testPanic(func() { Min([]bool{}, nil) }, "typed.Min: element type is not ordered", t)
+}
+
+func testPanic(f func(), err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+	f()
+}
+
+func isEven(i int) bool { return i%2 == 0 }
+
+func intLess(i, j int) bool { return i < j }
+
+func always(i, j int) bool { return true }
+
+func foldrConcat(i int, s string) string { return s + strconv.Itoa(i) }
+
+func foldlConcat(s string, i int) string { return s + strconv.Itoa(i) }
+
+type pair struct{ key, val int }
+
+func pairs() []pair { return []pair{{1, 0}, {2, 1}, {0, 2}, {2, 3}, {0, 4}} }
+
+func pairLess(a, b pair) bool { return a.key < b.key }
+
+// optional converts the results of Find, Max, and Min
+// to the convention used by package generics.
+func optional[T any](t T, ok bool) interface{} {
+	if !ok {
+		return nil
+	}
+	return t
+}
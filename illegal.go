@@ -8,6 +8,7 @@ package illegal
 
 import (
 	"reflect"
+	"strings"
 )
 
 // Convenience reflect.Type values
@@ -117,7 +118,15 @@ func convertSliceType(slc interface{}, typ reflect.Type) interface{} {
 	defer func() {
 		r := recover()
 		if r != nil {
-			panic("cannot convert type " + slice.Type().Elem().String() + " to " + typ.String())
+			msg := "cannot convert type " + slice.Type().Elem().String() + " to " + typ.String()
+			if typ.Kind() == reflect.Interface {
+				// Explain why, since otherwise it's
+				// often not obvious
+				if reasons := implements(slice.Type().Elem(), typ).reasons(); len(reasons) > 0 {
+					msg += " (" + strings.Join(reasons, "; ") + ")"
+				}
+			}
+			panic(msg)
 		}
 	}()
 
@@ -152,6 +152,7 @@ func TestConvertSlice(t *testing.T) {
 	testConvertSlice([]struct{}{struct{}{}}, []EmptyStructAlias{EmptyStructAlias{}}, EmptyStructAlias{}, nil, t)
 	testConvertSlice([]IntAlias{1, 2, 3}, []IntAlias2{1, 2, 3}, IntAlias2(0), nil, t)
 	testConvertSlice([]int{1, 2, 3}, []interface{}{1, 2, 3}, InterfaceReflectType, nil, t)
+	testConvertSlice([]int{1, 2, 3}, nil, reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), "illegal.ConvertSliceType: cannot convert type int to fmt.Stringer (missing method String)", t)
 
 	method1 := TypeWithMethod.Int
 	// method2 := (TypeWithMethod(3)).Int
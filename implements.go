@@ -0,0 +1,166 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"reflect"
+	"strings"
+)
+
+// An ImplementsReport describes whether, and if not
+// why not, a type implements an interface type.
+type ImplementsReport struct {
+	Type      reflect.Type
+	Interface reflect.Type
+
+	// The names of the interface's methods
+	// which the type has no method for.
+	Missing []string
+
+	// The interface's methods which the type
+	// has a method for, but with a different
+	// signature.
+	Mismatched []MethodMismatch
+
+	// The names of the interface's methods
+	// which are only in the method set of
+	// a pointer to the type, and not the
+	// type itself (that is, they are
+	// declared with a pointer receiver).
+	PointerReceiver []string
+}
+
+// A MethodMismatch describes a method whose
+// signature differs from that required by an
+// interface. Have and Want are function types
+// which do not include the receiver.
+type MethodMismatch struct {
+	Name string
+	Have reflect.Type
+	Want reflect.Type
+}
+
+// OK returns whether r.Type implements r.Interface.
+func (r *ImplementsReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Mismatched) == 0 && len(r.PointerReceiver) == 0
+}
+
+// String describes the report in a form
+// similar to the compiler's error messages:
+//
+//	T does not implement I:
+//		missing method Close
+//		wrong type for method Read: have func() int, want func([]uint8) (int, error)
+//		method Write has pointer receiver
+func (r *ImplementsReport) String() string {
+	if r.OK() {
+		return r.Type.String() + " implements " + r.Interface.String()
+	}
+	return r.Type.String() + " does not implement " + r.Interface.String() + ":\n\t" + strings.Join(r.reasons(), "\n\t")
+}
+
+func (r *ImplementsReport) reasons() []string {
+	var reasons []string
+	for _, name := range r.Missing {
+		reasons = append(reasons, "missing method "+name)
+	}
+	for _, m := range r.Mismatched {
+		reasons = append(reasons, "wrong type for method "+m.Name+": have "+m.Have.String()+", want "+m.Want.String())
+	}
+	for _, name := range r.PointerReceiver {
+		reasons = append(reasons, "method "+name+" has pointer receiver")
+	}
+	return reasons
+}
+
+// Implements reports whether the type of v
+// implements the interface type iface, and
+// if not, why not. If v is a reflect.Type,
+// that type is checked instead of v's type.
+// iface may either be a pointer to a value
+// of the interface type, as in
+//
+//	Implements(v, (*io.Reader)(nil))
+//
+// or a reflect.Type.
+//
+// Since reflect cannot see unexported methods
+// of non-interface types, an interface with
+// unexported methods is reported as missing
+// them, even if they are declared in the
+// same package as the interface.
+//
+// Implements panics if v is nil, or if iface
+// does not denote an interface type.
+func Implements(v, iface interface{}) *ImplementsReport {
+	typ, ok := v.(reflect.Type)
+	if !ok {
+		typ = reflect.TypeOf(v)
+	}
+	if typ == nil {
+		panic("illegal.Implements: passed nil value")
+	}
+
+	ifaceType, ok := iface.(reflect.Type)
+	if !ok {
+		ifaceType = reflect.TypeOf(iface)
+		if ifaceType != nil && ifaceType.Kind() == reflect.Ptr {
+			ifaceType = ifaceType.Elem()
+		}
+	}
+	if ifaceType == nil || ifaceType.Kind() != reflect.Interface {
+		panic("illegal.Implements: passed non-interface type " + typeString(ifaceType))
+	}
+
+	return implements(typ, ifaceType)
+}
+
+func implements(typ, iface reflect.Type) *ImplementsReport {
+	r := &ImplementsReport{Type: typ, Interface: iface}
+	for i := 0; i < iface.NumMethod(); i++ {
+		want := iface.Method(i)
+		if have, ok := methodSignature(typ, want.Name); ok {
+			if have != want.Type {
+				r.Mismatched = append(r.Mismatched, MethodMismatch{want.Name, have, want.Type})
+			}
+			continue
+		}
+		if typ.Kind() != reflect.Ptr && typ.Kind() != reflect.Interface {
+			if have, ok := methodSignature(reflect.PointerTo(typ), want.Name); ok {
+				if have != want.Type {
+					r.Mismatched = append(r.Mismatched, MethodMismatch{want.Name, have, want.Type})
+				} else {
+					r.PointerReceiver = append(r.PointerReceiver, want.Name)
+				}
+				continue
+			}
+		}
+		r.Missing = append(r.Missing, want.Name)
+	}
+	return r
+}
+
+// methodSignature returns the type of typ's
+// method with the given name, without the
+// receiver (which is included for methods
+// of non-interface types).
+func methodSignature(typ reflect.Type, name string) (reflect.Type, bool) {
+	m, ok := typ.MethodByName(name)
+	if !ok {
+		return nil, false
+	}
+	if typ.Kind() == reflect.Interface {
+		return m.Type, true
+	}
+	in := make([]reflect.Type, m.Type.NumIn()-1)
+	for i := range in {
+		in[i] = m.Type.In(i + 1)
+	}
+	out := make([]reflect.Type, m.Type.NumOut())
+	for i := range out {
+		out[i] = m.Type.Out(i)
+	}
+	return reflect.FuncOf(in, out, m.Type.IsVariadic()), true
+}
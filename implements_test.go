@@ -0,0 +1,96 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type implementsTestReader struct{}
+
+func (r implementsTestReader) Read(p []byte) (int, error) { return 0, nil }
+
+type implementsTestCloser struct{}
+
+func (c *implementsTestCloser) Close() error { return nil }
+
+type implementsTestBadReader struct{}
+
+func (r implementsTestBadReader) Read() int { return 0 }
+
+func (r *implementsTestBadReader) Close() {}
+
+func TestImplements(t *testing.T) {
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+	readCloserType := reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+	badRead := reflect.TypeOf(func() int { return 0 })
+	goodRead := reflect.TypeOf(func([]byte) (int, error) { return 0, nil })
+
+	// Implementation should succeed
+	testImplements(implementsTestReader{}, (*io.Reader)(nil), &ImplementsReport{Type: reflect.TypeOf(implementsTestReader{}), Interface: readerType}, nil, t)
+	testImplements(&implementsTestReader{}, readerType, &ImplementsReport{Type: reflect.TypeOf(&implementsTestReader{}), Interface: readerType}, nil, t)
+	testImplements(&implementsTestCloser{}, (*io.Closer)(nil), &ImplementsReport{Type: reflect.TypeOf(&implementsTestCloser{}), Interface: reflect.TypeOf((*io.Closer)(nil)).Elem()}, nil, t)
+	testImplements(readCloserType, (*io.Reader)(nil), &ImplementsReport{Type: readCloserType, Interface: readerType}, nil, t)
+	testImplements(3, (*interface{})(nil), &ImplementsReport{Type: reflect.TypeOf(3), Interface: InterfaceType}, nil, t)
+
+	// Implementation should fail
+	testImplements(3, (*io.Reader)(nil), &ImplementsReport{Type: reflect.TypeOf(3), Interface: readerType, Missing: []string{"Read"}}, nil, t)
+	testImplements(implementsTestCloser{}, (*io.Closer)(nil), &ImplementsReport{
+		Type:            reflect.TypeOf(implementsTestCloser{}),
+		Interface:       reflect.TypeOf((*io.Closer)(nil)).Elem(),
+		PointerReceiver: []string{"Close"},
+	}, nil, t)
+	testImplements(implementsTestBadReader{}, readCloserType, &ImplementsReport{
+		Type:       reflect.TypeOf(implementsTestBadReader{}),
+		Interface:  readCloserType,
+		Mismatched: []MethodMismatch{{"Close", reflect.TypeOf(func() {}), reflect.TypeOf(func() error { return nil })}, {"Read", badRead, goodRead}},
+	}, nil, t)
+	testImplements(readerType, readCloserType, &ImplementsReport{Type: readerType, Interface: readCloserType, Missing: []string{"Close"}}, nil, t)
+
+	// Implements should panic
+	testImplements(nil, (*io.Reader)(nil), nil, "illegal.Implements: passed nil value", t)
+	testImplements(3, 3, nil, "illegal.Implements: passed non-interface type int", t)
+	testImplements(3, nil, nil, "illegal.Implements: passed non-interface type <nil>", t)
+	testImplements(3, (*int)(nil), nil, "illegal.Implements: passed non-interface type int", t)
+}
+
+func testImplements(v, iface interface{}, expect *ImplementsReport, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	r := Implements(v, iface)
+	if !reflect.DeepEqual(r, expect) {
+		t.Errorf("Expected %+v; got %+v", expect, r)
+	}
+	if r.OK() != (len(expect.Missing)+len(expect.Mismatched)+len(expect.PointerReceiver) == 0) {
+		t.Errorf("Unexpected OK() == %v for %+v", r.OK(), r)
+	}
+}
+
+func TestImplementsReportString(t *testing.T) {
+	expect := "int implements interface {}"
+	if s := Implements(3, (*interface{})(nil)).String(); s != expect {
+		t.Errorf("Expected %q; got %q", expect, s)
+	}
+
+	expect = "illegal.implementsTestBadReader does not implement fmt.Stringer:\n\tmissing method String"
+	if s := Implements(implementsTestBadReader{}, (*fmt.Stringer)(nil)).String(); s != expect {
+		t.Errorf("Expected %q; got %q", expect, s)
+	}
+
+	expect = "illegal.implementsTestBadReader does not implement io.ReadCloser:\n" +
+		"\twrong type for method Close: have func(), want func() error\n" +
+		"\twrong type for method Read: have func() int, want func([]uint8) (int, error)"
+	if s := Implements(implementsTestBadReader{}, (*io.ReadCloser)(nil)).String(); s != expect {
+		t.Errorf("Expected %q; got %q", expect, s)
+	}
+}
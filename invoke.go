@@ -0,0 +1,149 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// Invoke calls obj's exported method with the given
+// name, passing args, and returns the method's
+// results. Each argument is used as-is if it is
+// assignable to the corresponding parameter, and
+// otherwise converted to the parameter's type if
+// it is convertible (following the same rules as
+// ConvertSlice, so a floating-point argument
+// passed for an integer parameter is truncated
+// toward zero, as by int(2.9)). Integers are not
+// converted to strings, since such a conversion
+// yields a rune rather than decimal digits, and a
+// slice is only converted to an array, or to a
+// pointer to one, if it has enough elements to
+// fill the array. A nil argument is passed as the
+// zero value of its parameter's type if that type
+// can be nil. For variadic methods, each argument
+// past the fixed parameters is treated as a single
+// element of the variadic parameter (as opposed to
+// a slice of elements).
+//
+// Invoke returns an error, rather than panicking,
+// if obj is nil, if it has no such method, or if
+// the arguments do not match the method's parameters.
+// If the method itself panics, the panic is not
+// recovered.
+//
+// Since reflect cannot call them, unexported
+// methods cannot be invoked.
+func Invoke(obj interface{}, method string, args ...interface{}) ([]interface{}, error) {
+	if obj == nil {
+		return nil, errors.New("illegal.Invoke: passed nil value")
+	}
+	v := reflect.ValueOf(obj)
+	m := v.MethodByName(method)
+	if !m.IsValid() {
+		msg := "illegal.Invoke: " + v.Type().String() + " has no method " + method
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			if _, ok := reflect.PointerTo(v.Type()).MethodByName(method); ok {
+				msg = "illegal.Invoke: method " + method + " has pointer receiver; pass a *" + v.Type().String()
+			}
+		}
+		return nil, errors.New(msg)
+	}
+
+	in, err := callArgs(m.Type(), args)
+	if err != "" {
+		return nil, errors.New("illegal.Invoke: " + err + " in call to " + method)
+	}
+	out := m.Call(in)
+
+	ret := make([]interface{}, len(out))
+	for i, o := range out {
+		ret[i] = o.Interface()
+	}
+	return ret, nil
+}
+
+// callArgs converts args to the parameter types
+// of fType, returning an error message, or ""
+// if there is no error.
+func callArgs(fType reflect.Type, args []interface{}) ([]reflect.Value, string) {
+	n := fType.NumIn()
+	if fType.IsVariadic() {
+		if len(args) < n-1 {
+			return nil, "not enough arguments (have " + strconv.Itoa(len(args)) + ", want at least " + strconv.Itoa(n-1) + ")"
+		}
+	} else if len(args) != n {
+		return nil, "wrong number of arguments (have " + strconv.Itoa(len(args)) + ", want " + strconv.Itoa(n) + ")"
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var typ reflect.Type
+		if fType.IsVariadic() && i >= n-1 {
+			typ = fType.In(n - 1).Elem()
+		} else {
+			typ = fType.In(i)
+		}
+
+		val, reason, ok := convertArg(arg, typ)
+		if !ok {
+			msg := "cannot use argument " + strconv.Itoa(i) + " (type " + typeString(reflect.TypeOf(arg)) + ") as type " + typ.String()
+			if reason != "" {
+				msg += " (" + reason + ")"
+			}
+			return nil, msg
+		}
+		in[i] = val
+	}
+	return in, ""
+}
+
+// convertArg returns arg as a value of type typ,
+// if it is assignable or convertible to typ. If
+// it is not, convertArg may also return a reason
+// to add to the error message.
+func convertArg(arg interface{}, typ reflect.Type) (reflect.Value, string, bool) {
+	if arg == nil {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan,
+			reflect.Func, reflect.Interface, reflect.UnsafePointer:
+			return reflect.Zero(typ), "", true
+		}
+		return reflect.Value{}, "", false
+	}
+
+	val := reflect.ValueOf(arg)
+	if val.Type().AssignableTo(typ) {
+		return val, "", true
+	}
+	if !val.Type().ConvertibleTo(typ) {
+		return reflect.Value{}, "", false
+	}
+
+	// reflect.Value.Convert panics, rather than
+	// failing, if a slice is too short for the
+	// array it is converted to
+	if val.Kind() == reflect.Slice {
+		arr := typ
+		if arr.Kind() == reflect.Ptr {
+			arr = arr.Elem()
+		}
+		if arr.Kind() == reflect.Array && val.Len() < arr.Len() {
+			return reflect.Value{}, "slice has length " + strconv.Itoa(val.Len()) + ", want at least " + strconv.Itoa(arr.Len()), false
+		}
+	}
+
+	if typ.Kind() == reflect.String {
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return reflect.Value{}, "", false
+		}
+	}
+
+	return val.Convert(typ), "", true
+}
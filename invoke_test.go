@@ -0,0 +1,88 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type invokeTestType struct {
+	n int
+}
+
+func (i invokeTestType) Add(a, b int) int { return i.n + a + b }
+
+func (i invokeTestType) Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(format, args...)
+}
+
+func (i invokeTestType) Divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+
+func (i invokeTestType) Len(s []int) int { return len(s) }
+
+func (i invokeTestType) Take(a [4]int) int { return a[0] + a[3] }
+
+func (i invokeTestType) TakePtr(a *[2]int) int { return a[1] }
+
+func (i invokeTestType) Name(s string) string { return s }
+
+func (i *invokeTestType) Set(n int) { i.n = n }
+
+func TestInvoke(t *testing.T) {
+	v := invokeTestType{1}
+
+	// Invoke should succeed
+	testInvoke(v, "Add", []interface{}{2, 3}, []interface{}{6}, nil, t)
+	testInvoke(v, "Add", []interface{}{int8(2), IntAlias(3)}, []interface{}{6}, nil, t)
+	testInvoke(v, "Add", []interface{}{2.9, 3}, []interface{}{6}, nil, t)
+	testInvoke(v, "Sprintf", []interface{}{"%d-%s"}, []interface{}{"%!d(MISSING)-%!s(MISSING)"}, nil, t)
+	testInvoke(v, "Sprintf", []interface{}{"%d-%s", 1, "a"}, []interface{}{"1-a"}, nil, t)
+	testInvoke(v, "Divide", []interface{}{1, 2}, []interface{}{0.5, nil}, nil, t)
+	testInvoke(v, "Divide", []interface{}{1, 0}, []interface{}{0.0, errors.New("division by zero")}, nil, t)
+	testInvoke(v, "Len", []interface{}{nil}, []interface{}{0}, nil, t)
+	testInvoke(v, "Take", []interface{}{[]int{1, 2, 3, 4, 5}}, []interface{}{5}, nil, t)
+	testInvoke(v, "TakePtr", []interface{}{[]int{1, 2}}, []interface{}{2}, nil, t)
+	testInvoke(v, "Name", []interface{}{[]byte("a")}, []interface{}{"a"}, nil, t)
+	testInvoke(&v, "Add", []interface{}{2, 3}, []interface{}{6}, nil, t)
+	testInvoke(&v, "Set", []interface{}{5}, []interface{}{}, nil, t)
+	if v.n != 5 {
+		t.Errorf("Expected Set to modify receiver; got %v", v.n)
+	}
+
+	// Invoke should fail
+	testInvoke(nil, "Add", nil, nil, "illegal.Invoke: passed nil value", t)
+	testInvoke(v, "Sub", nil, nil, "illegal.Invoke: illegal.invokeTestType has no method Sub", t)
+	testInvoke(v, "Set", []interface{}{5}, nil, "illegal.Invoke: method Set has pointer receiver; pass a *illegal.invokeTestType", t)
+	testInvoke(v, "Add", []interface{}{1}, nil, "illegal.Invoke: wrong number of arguments (have 1, want 2) in call to Add", t)
+	testInvoke(v, "Sprintf", nil, nil, "illegal.Invoke: not enough arguments (have 0, want at least 1) in call to Sprintf", t)
+	testInvoke(v, "Add", []interface{}{1, "a"}, nil, "illegal.Invoke: cannot use argument 1 (type string) as type int in call to Add", t)
+	testInvoke(v, "Add", []interface{}{1, nil}, nil, "illegal.Invoke: cannot use argument 1 (type <nil>) as type int in call to Add", t)
+	testInvoke(v, "Take", []interface{}{[]int{1, 2}}, nil,
+		"illegal.Invoke: cannot use argument 0 (type []int) as type [4]int (slice has length 2, want at least 4) in call to Take", t)
+	testInvoke(v, "TakePtr", []interface{}{[]int{}}, nil,
+		"illegal.Invoke: cannot use argument 0 (type []int) as type *[2]int (slice has length 0, want at least 2) in call to TakePtr", t)
+	testInvoke(v, "Name", []interface{}{65}, nil, "illegal.Invoke: cannot use argument 0 (type int) as type string in call to Name", t)
+}
+
+func testInvoke(obj interface{}, method string, args []interface{}, expect []interface{}, err interface{}, t *testing.T) {
+	ret, e := Invoke(obj, method, args...)
+	if e != nil || err != nil {
+		if err == nil || e == nil || e.Error() != err {
+			t.Errorf("Expected error %v; got %v", err, e)
+		}
+		return
+	}
+	if !reflect.DeepEqual(ret, expect) {
+		t.Errorf("Expected %v; got %v", expect, ret)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// A Matcher is a runtime equivalent of a type
+// switch. It holds a list of cases, each of which
+// is a function of the form func(T) R, where T may
+// differ between cases but R may not.
+//
+// A Matcher is safe for concurrent use.
+type Matcher struct {
+	cases   []reflect.Value
+	resType reflect.Type
+}
+
+// NewMatcher validates cases, and returns a Matcher
+// which matches against them. Since validation only
+// happens once, it is cheaper to create a Matcher
+// and reuse it than to call Match repeatedly.
+//
+// NewMatcher panics if no cases are given, if any
+// case is not a function taking one parameter and
+// returning one result, or if the cases' result
+// types differ.
+func NewMatcher(cases ...interface{}) *Matcher {
+	m, err := newMatcher(cases)
+	if err != "" {
+		panic("illegal.NewMatcher: " + err)
+	}
+	return m
+}
+
+// Match calls the first case whose parameter type
+// matches v's dynamic type, and returns its result.
+// A case matches if its parameter type is identical
+// to v's type, or is an interface type which v's
+// type implements. Thus, a case with a parameter
+// of type interface{} acts as a default case. A
+// nil v matches only a case whose parameter type
+// is an interface type with no methods, which is
+// called with a nil argument.
+//
+// If no case matches, Match panics with a message
+// naming v's type.
+func (m *Matcher) Match(v interface{}) interface{} {
+	if ret, ok := m.match(v); ok {
+		return ret
+	}
+	panic("illegal.Matcher.Match: unhandled type " + typeString(reflect.TypeOf(v)))
+}
+
+// Match is equivalent to NewMatcher(cases...).Match(v),
+// except that its panics are prefixed with "illegal.Match".
+func Match(v interface{}, cases ...interface{}) interface{} {
+	m, err := newMatcher(cases)
+	if err != "" {
+		panic("illegal.Match: " + err)
+	}
+	if ret, ok := m.match(v); ok {
+		return ret
+	}
+	panic("illegal.Match: unhandled type " + typeString(reflect.TypeOf(v)))
+}
+
+// newMatcher returns an error message,
+// or "" if there is no error.
+func newMatcher(cases []interface{}) (*Matcher, string) {
+	if len(cases) == 0 {
+		return nil, "no cases given"
+	}
+	m := &Matcher{cases: make([]reflect.Value, len(cases))}
+	for i, c := range cases {
+		f := reflect.ValueOf(c)
+		if f.Kind() != reflect.Func {
+			return nil, "case " + strconv.Itoa(i) + " is not a function"
+		}
+		fType := f.Type()
+		if fType.NumIn() != 1 || fType.NumOut() != 1 || fType.IsVariadic() {
+			return nil, "case " + strconv.Itoa(i) + " has type " + fType.String() + "; want func(T) R"
+		}
+		if i == 0 {
+			m.resType = fType.Out(0)
+		} else if fType.Out(0) != m.resType {
+			return nil, "case " + strconv.Itoa(i) + " returns " + fType.Out(0).String() + "; case 0 returns " + m.resType.String()
+		}
+		m.cases[i] = f
+	}
+	return m, ""
+}
+
+func (m *Matcher) match(v interface{}) (interface{}, bool) {
+	typ := reflect.TypeOf(v)
+	for _, c := range m.cases {
+		param := c.Type().In(0)
+		var arg reflect.Value
+		switch {
+		case typ == nil:
+			if param.Kind() != reflect.Interface || param.NumMethod() != 0 {
+				continue
+			}
+			arg = reflect.Zero(param)
+		case typ == param || (param.Kind() == reflect.Interface && typ.Implements(param)):
+			arg = reflect.ValueOf(v)
+		default:
+			continue
+		}
+		return c.Call([]reflect.Value{arg})[0].Interface(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []interface{}{
+		func(i int) string { return "int " + fmt.Sprint(i) },
+		func(s string) string { return "string " + s },
+		func(e error) string { return "error " + e.Error() },
+		func(s fmt.Stringer) string { return "stringer" },
+	}
+	withDefault := append(cases, func(v interface{}) string { return fmt.Sprintf("default %v", v) })
+
+	// Match should succeed
+	testMatch(1, cases, "int 1", nil, t)
+	testMatch("a", cases, "string a", nil, t)
+	testMatch(errors.New("e"), cases, "error e", nil, t)
+	testMatch(TypeWithMethod(0), withDefault, "default 0", nil, t)
+	testMatch(1.5, withDefault, "default 1.5", nil, t)
+	testMatch(nil, withDefault, "default <nil>", nil, t)
+	testMatch(int8(1), []interface{}{func(i int8) int8 { return i + 1 }}, int8(2), nil, t)
+
+	// Match should panic
+	testMatch(1.5, cases, nil, "illegal.Match: unhandled type float64", t)
+	testMatch(nil, cases, nil, "illegal.Match: unhandled type <nil>", t)
+	testMatch(IntAlias(1), cases, nil, "illegal.Match: unhandled type illegal.IntAlias", t)
+	testMatch(1, nil, nil, "illegal.Match: no cases given", t)
+	testMatch(1, []interface{}{func(int) int { return 0 }, 3}, nil, "illegal.Match: case 1 is not a function", t)
+	testMatch(1, []interface{}{func(int, int) int { return 0 }}, nil, "illegal.Match: case 0 has type func(int, int) int; want func(T) R", t)
+	testMatch(1, []interface{}{func(int) {}}, nil, "illegal.Match: case 0 has type func(int); want func(T) R", t)
+	testMatch(1, []interface{}{func(...int) int { return 0 }}, nil, "illegal.Match: case 0 has type func(...int) int; want func(T) R", t)
+	testMatch(1, []interface{}{func(int) int { return 0 }, func(string) string { return "" }}, nil, "illegal.Match: case 1 returns string; case 0 returns int", t)
+}
+
+func testMatch(v interface{}, cases []interface{}, expect interface{}, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	ret := Match(v, cases...)
+	if !reflect.DeepEqual(ret, expect) {
+		t.Errorf("Expected %v; got %v", expect, ret)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher(func(i int) int { return i * 2 }, func(s fmt.Stringer) int { return -1 })
+	if ret := m.Match(3); ret != 6 {
+		t.Errorf("Expected 6; got %v", ret)
+	}
+	if ret := m.Match(&ImplementsReport{Type: InterfaceType, Interface: InterfaceType}); ret != -1 {
+		t.Errorf("Expected -1; got %v", ret)
+	}
+
+	// A nil v skips interfaces with methods
+	n := NewMatcher(func(e error) string { return "error" }, func(v interface{}) string { return fmt.Sprint("any ", v) })
+	if ret := n.Match(nil); ret != "any <nil>" {
+		t.Errorf("Expected any <nil>; got %v", ret)
+	}
+	func() {
+		expect := "illegal.Matcher.Match: unhandled type <nil>"
+		defer func() {
+			if r := recover(); r != expect {
+				t.Errorf("Expected error %v; got %v", expect, r)
+			}
+		}()
+		m.Match(nil)
+	}()
+
+	func() {
+		expect := "illegal.Matcher.Match: unhandled type string"
+		defer func() {
+			if r := recover(); r != expect {
+				t.Errorf("Expected error %v; got %v", expect, r)
+			}
+		}()
+		m.Match("a")
+	}()
+
+	func() {
+		expect := "illegal.NewMatcher: case 0 is not a function"
+		defer func() {
+			if r := recover(); r != expect {
+				t.Errorf("Expected error %v; got %v", expect, r)
+			}
+		}()
+		NewMatcher(3)
+	}()
+}
@@ -0,0 +1,53 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"reflect"
+)
+
+// IsNil returns whether v is nil, either
+// because it is a nil interface, or because
+// it holds a nil value of a type which can
+// be nil: a pointer, map, slice, channel,
+// function, or unsafe.Pointer. Note that
+// in the latter case, v == nil is false.
+//
+// For example, all of the following are true:
+//
+//	IsNil(nil)
+//	IsNil((*int)(nil))
+//	IsNil(map[string]int(nil))
+//	IsNil(error(nil))
+//
+// IsNil does not look through pointers;
+// a non-nil pointer to a nil value is not nil.
+func IsNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan,
+		reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return val.IsNil()
+	}
+	return false
+}
+
+// Normalize returns a nil interface if IsNil(v),
+// and v otherwise. It can be used to make typed
+// nils compare equal to nil:
+//
+//	var p *int
+//	var v interface{} = p
+//	v == nil            // false
+//	Normalize(v) == nil // true
+func Normalize(v interface{}) interface{} {
+	if IsNil(v) {
+		return nil
+	}
+	return v
+}
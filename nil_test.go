@@ -0,0 +1,66 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"testing"
+	"unsafe"
+)
+
+type nilTestError struct{}
+
+func (n *nilTestError) Error() string { return "" }
+
+func TestIsNil(t *testing.T) {
+	// IsNil should return true
+	testIsNil(nil, true, t)
+	testIsNil((*int)(nil), true, t)
+	testIsNil(map[string]int(nil), true, t)
+	testIsNil([]int(nil), true, t)
+	testIsNil((chan int)(nil), true, t)
+	testIsNil((func())(nil), true, t)
+	testIsNil(unsafe.Pointer(nil), true, t)
+	testIsNil(error(nil), true, t)
+	testIsNil(error((*nilTestError)(nil)), true, t)
+
+	// IsNil should return false
+	i := 0
+	testIsNil(0, false, t)
+	testIsNil("", false, t)
+	testIsNil(struct{}{}, false, t)
+	testIsNil(&i, false, t)
+	testIsNil(map[string]int{}, false, t)
+	testIsNil([]int{}, false, t)
+	testIsNil(make(chan int), false, t)
+	testIsNil(func() {}, false, t)
+	testIsNil(unsafe.Pointer(&i), false, t)
+	testIsNil(new(*int), false, t)
+	testIsNil(&nilTestError{}, false, t)
+}
+
+func testIsNil(v interface{}, expect bool, t *testing.T) {
+	if IsNil(v) != expect {
+		t.Errorf("Expected IsNil(%#v) to be %v; got %v", v, expect, !expect)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	var err error = (*nilTestError)(nil)
+	if err == nil {
+		t.Fatalf("Expected typed nil to compare non-nil")
+	}
+	if Normalize(err) != nil {
+		t.Errorf("Expected Normalize(%#v) to be nil; got %#v", err, Normalize(err))
+	}
+	if Normalize([]int(nil)) != nil {
+		t.Errorf("Expected Normalize([]int(nil)) to be nil; got %#v", Normalize([]int(nil)))
+	}
+	if Normalize(nil) != nil {
+		t.Errorf("Expected Normalize(nil) to be nil; got %#v", Normalize(nil))
+	}
+	if v := Normalize(3); v != 3 {
+		t.Errorf("Expected 3; got %#v", v)
+	}
+}
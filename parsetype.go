@@ -0,0 +1,260 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParseType parses expr, which must be a Go type
+// expression such as "map[string][]*int", and
+// returns the type it denotes. Named types are
+// resolved using the type registry (see TypeByName);
+// a name qualified by a package name, such as
+// "y.T", also matches a type registered as
+// "github.com/x/y.T", so long as only one such
+// type is registered.
+//
+// ParseType supports named types, pointers,
+// slices, arrays, maps, channels (including
+// directional channels), function types
+// (including variadic ones), struct types
+// (including tags and embedded fields), and
+// the empty interface. Since reflect cannot
+// construct them, non-empty interface literals
+// and structs with unexported fields are
+// not supported.
+func ParseType(expr string) (reflect.Type, error) {
+	return parseType("illegal.ParseType: ", expr, lookupRegistered)
+}
+
+// ParseTypeLookup is like ParseType, except that
+// named types are resolved by calling lookup,
+// which is passed either a bare name ("int") or
+// a name qualified by a package name ("time.Time"),
+// and which should return nil if there is no
+// such type.
+func ParseTypeLookup(expr string, lookup func(name string) reflect.Type) (reflect.Type, error) {
+	return parseType("illegal.ParseTypeLookup: ", expr, lookup)
+}
+
+func parseType(prefix, expr string, lookup func(string) reflect.Type) (typ reflect.Type, err error) {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, errors.New(prefix + "cannot parse " + strconv.Quote(expr) + ": " + err.Error())
+	}
+
+	// The reflect constructors panic on some
+	// invalid inputs which we don't check for
+	// ahead of time (such as types which are
+	// too large); report those as errors too.
+	defer func() {
+		if r := recover(); r != nil {
+			typ, err = nil, errors.New(prefix+fmt.Sprint(r))
+		}
+	}()
+
+	p := typeParser{lookup: lookup}
+	typ = p.parse(e)
+	if p.err != "" {
+		return nil, errors.New(prefix + p.err)
+	}
+	return typ, nil
+}
+
+// typeParser records the first error it
+// encounters in err; once err is set, the
+// types it returns are meaningless.
+type typeParser struct {
+	lookup func(string) reflect.Type
+	err    string
+}
+
+func (p *typeParser) fail(format string, args ...interface{}) reflect.Type {
+	if p.err == "" {
+		p.err = fmt.Sprintf(format, args...)
+	}
+	return InterfaceType
+}
+
+func (p *typeParser) parse(e ast.Expr) reflect.Type {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return p.named(e.Name)
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return p.fail("invalid qualified type name")
+		}
+		return p.named(pkg.Name + "." + e.Sel.Name)
+	case *ast.ParenExpr:
+		return p.parse(e.X)
+	case *ast.StarExpr:
+		return reflect.PointerTo(p.parse(e.X))
+	case *ast.ArrayType:
+		elem := p.parse(e.Elt)
+		if e.Len == nil {
+			return reflect.SliceOf(elem)
+		}
+		lit, ok := e.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return p.fail("array length must be an integer literal")
+		}
+		n, err := strconv.ParseInt(lit.Value, 0, 0)
+		if err != nil {
+			return p.fail("invalid array length %s", lit.Value)
+		}
+		return reflect.ArrayOf(int(n), elem)
+	case *ast.MapType:
+		key, elem := p.parse(e.Key), p.parse(e.Value)
+		if !key.Comparable() {
+			return p.fail("invalid map key type %v", key)
+		}
+		return reflect.MapOf(key, elem)
+	case *ast.ChanType:
+		dir := reflect.BothDir
+		switch e.Dir {
+		case ast.SEND:
+			dir = reflect.SendDir
+		case ast.RECV:
+			dir = reflect.RecvDir
+		}
+		return reflect.ChanOf(dir, p.parse(e.Value))
+	case *ast.FuncType:
+		in, variadic := p.fields(e.Params)
+		out, _ := p.fields(e.Results)
+		return reflect.FuncOf(in, out, variadic)
+	case *ast.StructType:
+		return p.structType(e)
+	case *ast.InterfaceType:
+		if len(e.Methods.List) != 0 {
+			return p.fail("cannot construct non-empty interface types")
+		}
+		return InterfaceType
+	}
+	return p.fail("unsupported type expression %T", e)
+}
+
+func (p *typeParser) named(name string) reflect.Type {
+	typ := p.lookup(name)
+	if typ == nil {
+		return p.fail("unknown type name %s", name)
+	}
+	return typ
+}
+
+// fields returns one type per parameter or result
+// (so func(a, b int) yields two ints), and whether
+// the last parameter is variadic.
+func (p *typeParser) fields(list *ast.FieldList) ([]reflect.Type, bool) {
+	if list == nil {
+		return nil, false
+	}
+	var types []reflect.Type
+	variadic := false
+	for _, f := range list.List {
+		expr := f.Type
+		if ell, ok := expr.(*ast.Ellipsis); ok {
+			// The parser only allows ... on
+			// a sole, final parameter
+			variadic = true
+			expr = &ast.ArrayType{Elt: ell.Elt}
+		}
+		typ := p.parse(expr)
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for j := 0; j < n; j++ {
+			types = append(types, typ)
+		}
+	}
+	return types, variadic
+}
+
+func (p *typeParser) structType(e *ast.StructType) reflect.Type {
+	var fields []reflect.StructField
+	for _, f := range e.Fields.List {
+		typ := p.parse(f.Type)
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return p.fail("invalid struct tag %s", f.Tag.Value)
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		if len(f.Names) == 0 {
+			// Embedded fields are named after
+			// their (possibly pointed-to) type
+			name := embeddedName(f.Type)
+			if name == "" {
+				return p.fail("invalid embedded field type")
+			}
+			if !token.IsExported(name) {
+				return p.fail("cannot construct struct with unexported field %s", name)
+			}
+			fields = append(fields, reflect.StructField{Name: name, Type: typ, Tag: tag, Anonymous: true})
+			continue
+		}
+		for _, n := range f.Names {
+			if !token.IsExported(n.Name) {
+				return p.fail("cannot construct struct with unexported field %s", n.Name)
+			}
+			fields = append(fields, reflect.StructField{Name: n.Name, Type: typ, Tag: tag})
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+func embeddedName(e ast.Expr) string {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+// lookupRegistered resolves name using the type
+// registry, falling back to matching a package
+// name against the last element of registered
+// types' import paths.
+func lookupRegistered(name string) reflect.Type {
+	if typ := TypeByName(name); typ != nil {
+		return typ
+	}
+	dot := strings.LastIndex(name, ".")
+	if dot == -1 {
+		return nil
+	}
+	suffix := "/" + name
+
+	registry.RLock()
+	defer registry.RUnlock()
+	var found reflect.Type
+	for qualified, typ := range registry.types {
+		if strings.HasSuffix(qualified, suffix) {
+			if found != nil && found != typ {
+				// Ambiguous; refuse to guess
+				return nil
+			}
+			found = typ
+		}
+	}
+	return found
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ParseTypeTestType struct{}
+
+func TestParseType(t *testing.T) {
+	RegisterType(ParseTypeTestType{})
+
+	// ParseType should succeed
+	testParseType("int", reflect.TypeOf(0), nil, t)
+	testParseType("map[string][]*int", reflect.TypeOf(map[string][]*int{}), nil, t)
+	testParseType("[4]byte", reflect.TypeOf([4]byte{}), nil, t)
+	testParseType("[0x10]rune", reflect.TypeOf([16]rune{}), nil, t)
+	testParseType("(*error)", reflect.TypeOf((*error)(nil)), nil, t)
+	testParseType("chan int", reflect.TypeOf(make(chan int)), nil, t)
+	testParseType("<-chan int", reflect.TypeOf(make(<-chan int)), nil, t)
+	testParseType("chan<- []string", reflect.TypeOf(make(chan<- []string)), nil, t)
+	testParseType("chan (<-chan int)", reflect.TypeOf(make(chan (<-chan int))), nil, t)
+	testParseType("func()", reflect.TypeOf(func() {}), nil, t)
+	testParseType("func(a, b int, s string) (bool, error)", reflect.TypeOf(func(int, int, string) (bool, error) { return false, nil }), nil, t)
+	testParseType("func(string, ...any) int", reflect.TypeOf(func(string, ...interface{}) int { return 0 }), nil, t)
+	testParseType("interface{}", InterfaceType, nil, t)
+	testParseType("time.Time", reflect.TypeOf(time.Time{}), nil, t)
+	testParseType("[]illegal.ParseTypeTestType", reflect.TypeOf([]ParseTypeTestType{}), nil, t)
+	testParseType("struct{}", reflect.TypeOf(struct{}{}), nil, t)
+	testParseType("struct { A, B int `key:\"a\"`; C *string }", reflect.TypeOf(struct {
+		A, B int `key:"a"`
+		C    *string
+	}{}), nil, t)
+	testParseType("struct { illegal.ParseTypeTestType; Duration time.Duration }", reflect.TypeOf(struct {
+		ParseTypeTestType
+		Duration time.Duration
+	}{}), nil, t)
+
+	// ParseType should fail
+	testParseTypeErrorPrefix("", "illegal.ParseType: cannot parse \"\": ", t)
+	testParseType("foo", nil, "illegal.ParseType: unknown type name foo", t)
+	testParseType("[]bar.Baz", nil, "illegal.ParseType: unknown type name bar.Baz", t)
+	testParseType("map[[]int]int", nil, "illegal.ParseType: invalid map key type []int", t)
+	testParseType("[n]int", nil, "illegal.ParseType: array length must be an integer literal", t)
+	testParseTypeErrorPrefix("func(...int, int)", "illegal.ParseType: cannot parse \"func(...int, int)\": ", t)
+	testParseType("interface{ Error() string }", nil, "illegal.ParseType: cannot construct non-empty interface types", t)
+	testParseType("struct{ a int }", nil, "illegal.ParseType: cannot construct struct with unexported field a", t)
+	testParseType("struct { *time.Time; Duration time.Duration }", nil, "illegal.ParseType: reflect: embedded type with methods not implemented if there is more than one field", t)
+	testParseType("1 + 2", nil, "illegal.ParseType: unsupported type expression *ast.BinaryExpr", t)
+}
+
+func testParseType(expr string, expect reflect.Type, err interface{}, t *testing.T) {
+	typ, e := ParseType(expr)
+	if e != nil || err != nil {
+		if err == nil || e == nil || e.Error() != err {
+			t.Errorf("Expected error %v; got %v", err, e)
+		}
+		return
+	}
+	if typ != expect {
+		t.Errorf("Expected ParseType(%q) to be %v; got %v", expr, expect, typ)
+	}
+}
+
+// testParseTypeErrorPrefix checks only the prefix
+// of errors which include go/parser's messages,
+// since those vary between Go releases.
+func testParseTypeErrorPrefix(expr, prefix string, t *testing.T) {
+	if _, e := ParseType(expr); e == nil || !strings.HasPrefix(e.Error(), prefix) {
+		t.Errorf("Expected error with prefix %q; got %v", prefix, e)
+	}
+}
+
+func TestParseTypeLookup(t *testing.T) {
+	lookup := func(name string) reflect.Type {
+		switch name {
+		case "int":
+			return reflect.TypeOf(0)
+		case "Thing":
+			return reflect.TypeOf(ParseTypeTestType{})
+		}
+		return nil
+	}
+
+	typ, err := ParseTypeLookup("map[int]*Thing", lookup)
+	if expect := reflect.TypeOf(map[int]*ParseTypeTestType{}); typ != expect || err != nil {
+		t.Errorf("Expected (%v, <nil>); got (%v, %v)", expect, typ, err)
+	}
+
+	_, err = ParseTypeLookup("[]string", lookup)
+	if expect := errors.New("illegal.ParseTypeLookup: unknown type name string"); !reflect.DeepEqual(err, expect) {
+		t.Errorf("Expected error %v; got %v", expect, err)
+	}
+}
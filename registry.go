@@ -0,0 +1,106 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var registry struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// RegisterType registers example's type so that
+// it can be looked up by its qualified name using
+// TypeByName. The qualified name of a type is its
+// package's import path followed by a dot and the
+// type's name (for example, "time.Time" or
+// "github.com/x/y.T"). If example is a pointer,
+// its element type is registered instead, which
+// allows interface types to be registered:
+//
+//	RegisterType((*io.Reader)(nil))
+//
+// Registering the same type more than once has
+// no effect.
+//
+// RegisterType panics if example is nil, if
+// the type to register is not a named type, or
+// if a different type with the same qualified
+// name has already been registered (which can
+// happen with types declared inside functions).
+func RegisterType(example interface{}) {
+	typ := reflect.TypeOf(example)
+	if typ == nil {
+		panic("illegal.RegisterType: passed nil value")
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Name() == "" {
+		panic("illegal.RegisterType: cannot register unnamed type " + typ.String())
+	}
+	if err := registerType(qualifiedName(typ), typ); err != "" {
+		panic("illegal.RegisterType: " + err)
+	}
+}
+
+// TypeByName returns the type registered under
+// the given qualified name, or nil if there is
+// no such type. See RegisterType for the format
+// of qualified names.
+//
+// The predeclared types (including the aliases
+// byte, rune, and any) and the types error,
+// fmt.Stringer, time.Time, and time.Duration
+// are registered automatically.
+func TypeByName(name string) reflect.Type {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.types[name]
+}
+
+// registerType returns an error message,
+// or "" if there is no error.
+func registerType(name string, typ reflect.Type) string {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.types == nil {
+		registry.types = make(map[string]reflect.Type)
+	}
+	if old, ok := registry.types[name]; ok && old != typ {
+		return "type name " + name + " is ambiguous"
+	}
+	registry.types[name] = typ
+	return ""
+}
+
+func qualifiedName(typ reflect.Type) string {
+	if typ.PkgPath() == "" {
+		return typ.Name()
+	}
+	return typ.PkgPath() + "." + typ.Name()
+}
+
+func init() {
+	for _, example := range []interface{}{
+		false, "",
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0), uintptr(0),
+		float32(0), float64(0), complex64(0), complex128(0),
+		(*error)(nil), (*fmt.Stringer)(nil), time.Time{}, time.Duration(0),
+	} {
+		RegisterType(example)
+	}
+
+	// Aliases don't have names of their own
+	registerType("byte", reflect.TypeOf(byte(0)))
+	registerType("rune", reflect.TypeOf(rune(0)))
+	registerType("any", reflect.TypeOf((*interface{})(nil)).Elem())
+}
@@ -0,0 +1,75 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type RegistryTestType struct{}
+
+func TestRegisterType(t *testing.T) {
+	testRegisterType(RegistryTestType{}, "github.com/joshlf13/illegal.RegistryTestType", reflect.TypeOf(RegistryTestType{}), nil, t)
+	// Registering twice should be a no-op
+	testRegisterType(RegistryTestType{}, "github.com/joshlf13/illegal.RegistryTestType", reflect.TypeOf(RegistryTestType{}), nil, t)
+	testRegisterType(&RegistryTestType{}, "github.com/joshlf13/illegal.RegistryTestType", reflect.TypeOf(RegistryTestType{}), nil, t)
+	testRegisterType((*io.Reader)(nil), "io.Reader", reflect.TypeOf((*io.Reader)(nil)).Elem(), nil, t)
+	testRegisterType(IntAlias(0), "github.com/joshlf13/illegal.IntAlias", reflect.TypeOf(IntAlias(0)), nil, t)
+
+	testRegisterType(nil, "", nil, "illegal.RegisterType: passed nil value", t)
+	testRegisterType([]int{}, "", nil, "illegal.RegisterType: cannot register unnamed type []int", t)
+	testRegisterType((*[]int)(nil), "", nil, "illegal.RegisterType: cannot register unnamed type []int", t)
+
+	// Two distinct types declared in different
+	// scopes share the same qualified name
+	func() {
+		type registryLocal struct{}
+		testRegisterType(registryLocal{}, "github.com/joshlf13/illegal.registryLocal", reflect.TypeOf(registryLocal{}), nil, t)
+	}()
+	func() {
+		type registryLocal int
+		testRegisterType(registryLocal(0), "", nil, "illegal.RegisterType: type name github.com/joshlf13/illegal.registryLocal is ambiguous", t)
+	}()
+}
+
+func testRegisterType(example interface{}, name string, typ reflect.Type, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	RegisterType(example)
+	if got := TypeByName(name); got != typ {
+		t.Errorf("Expected TypeByName(%q) to be %v; got %v", name, typ, got)
+	}
+}
+
+func TestTypeByName(t *testing.T) {
+	testTypeByName("int", reflect.TypeOf(0), t)
+	testTypeByName("string", reflect.TypeOf(""), t)
+	testTypeByName("complex64", reflect.TypeOf(complex64(0)), t)
+	testTypeByName("byte", reflect.TypeOf(uint8(0)), t)
+	testTypeByName("rune", reflect.TypeOf(int32(0)), t)
+	testTypeByName("any", InterfaceType, t)
+	testTypeByName("error", reflect.TypeOf((*error)(nil)).Elem(), t)
+	testTypeByName("fmt.Stringer", reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), t)
+	testTypeByName("time.Time", reflect.TypeOf(time.Time{}), t)
+	testTypeByName("time.Duration", reflect.TypeOf(time.Duration(0)), t)
+	testTypeByName("[]int", nil, t)
+	testTypeByName("Time", nil, t)
+	testTypeByName("", nil, t)
+}
+
+func testTypeByName(name string, typ reflect.Type, t *testing.T) {
+	if got := TypeByName(name); got != typ {
+		t.Errorf("Expected TypeByName(%q) to be %v; got %v", name, typ, got)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"context"
+	"reflect"
+)
+
+// SelectRecv blocks until a receive operation
+// can proceed on one of the channels in chans,
+// which must be a slice of channels ([]chan T
+// or []<-chan T), performs it, and returns the
+// index of the chosen channel, the received
+// value, and whether the value was delivered by
+// a send (ok == false if the channel was closed).
+// If several channels are ready, one is chosen
+// pseudo-randomly.
+//
+// As with a select statement, nil channels are
+// never ready. If chans contains no non-nil
+// channels, SelectRecv blocks forever.
+//
+// SelectRecv panics if chans is not a slice
+// value, or if its element type is not a channel
+// type which allows receiving.
+func SelectRecv(chans interface{}) (chosen int, value interface{}, ok bool) {
+	cases, err := recvCases(chans)
+	if err != "" {
+		panic("illegal.SelectRecv: " + err)
+	}
+	chosen, val, ok := reflect.Select(cases)
+	return chosen, val.Interface(), ok
+}
+
+// SelectRecvContext is like SelectRecv, except
+// that it gives up if ctx is done before any
+// channel is ready. In that case, it returns
+// chosen == -1, a nil value, ok == false, and
+// ctx.Err(). Otherwise, the returned error is nil.
+//
+// SelectRecvContext panics under the same
+// conditions as SelectRecv, or if ctx is nil.
+func SelectRecvContext(ctx context.Context, chans interface{}) (chosen int, value interface{}, ok bool, err error) {
+	if ctx == nil {
+		panic("illegal.SelectRecvContext: passed nil Context")
+	}
+	cases, msg := recvCases(chans)
+	if msg != "" {
+		panic("illegal.SelectRecvContext: " + msg)
+	}
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	chosen, val, ok := reflect.Select(cases)
+	if chosen == len(cases)-1 {
+		return -1, nil, false, ctx.Err()
+	}
+	return chosen, val.Interface(), ok, nil
+}
+
+// Merge returns a channel (of type <-chan T)
+// which receives every value sent on any of the
+// channels in chans, which must be a slice of
+// channels ([]chan T or []<-chan T). The returned
+// channel is closed once every channel in chans
+// has been closed. Nil channels are ignored, so
+// if chans contains no non-nil channels, the
+// returned channel is closed immediately.
+//
+// Values are forwarded by a goroutine which exits
+// once all channels in chans have been closed;
+// until then, it blocks unless the returned
+// channel is drained. A caller which may stop
+// receiving early should use MergeContext, since
+// otherwise the goroutine leaks.
+//
+// Merge panics if chans is not a slice value,
+// or if its element type is not a channel
+// type which allows receiving.
+func Merge(chans interface{}) interface{} {
+	cases, err := recvCases(chans)
+	if err != "" {
+		panic("illegal.Merge: " + err)
+	}
+	return merge(context.Background(), chans, cases)
+}
+
+// MergeContext is like Merge, except that the
+// goroutine which forwards values also exits,
+// closing the returned channel, once ctx is
+// done; any value which has been received but
+// not yet forwarded is then dropped.
+//
+// MergeContext panics under the same conditions
+// as Merge, or if ctx is nil.
+func MergeContext(ctx context.Context, chans interface{}) interface{} {
+	if ctx == nil {
+		panic("illegal.MergeContext: passed nil Context")
+	}
+	cases, err := recvCases(chans)
+	if err != "" {
+		panic("illegal.MergeContext: " + err)
+	}
+	return merge(ctx, chans, cases)
+}
+
+func merge(ctx context.Context, chans interface{}, cases []reflect.SelectCase) interface{} {
+	elemType := reflect.TypeOf(chans).Elem().Elem()
+	out := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, elemType), 0)
+
+	open := 0
+	for _, c := range cases {
+		if !c.Chan.IsNil() {
+			open++
+		}
+	}
+
+	// The last case of each is ctx being done; if
+	// ctx can never be done, its channel is nil,
+	// and reflect.Select ignores it
+	done := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	cases = append(cases, done)
+	send := []reflect.SelectCase{{Dir: reflect.SelectSend, Chan: out}, done}
+
+	go func() {
+		defer out.Close()
+		for open > 0 {
+			chosen, val, ok := reflect.Select(cases)
+			if chosen == len(cases)-1 {
+				return
+			}
+			if !ok {
+				// Setting a nil channel makes
+				// reflect.Select ignore the case
+				cases[chosen].Chan = reflect.Zero(cases[chosen].Chan.Type())
+				open--
+				continue
+			}
+			send[0].Send = val
+			if chosen, _, _ := reflect.Select(send); chosen == 1 {
+				return
+			}
+		}
+	}()
+
+	return out.Convert(reflect.ChanOf(reflect.RecvDir, elemType)).Interface()
+}
+
+// recvCases returns an error message,
+// or "" if there is no error.
+func recvCases(chans interface{}) ([]reflect.SelectCase, string) {
+	slice := reflect.ValueOf(chans)
+	if slice.Kind() != reflect.Slice {
+		return nil, "passed non-slice value"
+	}
+	elemType := slice.Type().Elem()
+	if elemType.Kind() != reflect.Chan || elemType.ChanDir()&reflect.RecvDir == 0 {
+		return nil, "slice element type " + elemType.String() + " is not a receivable channel type"
+	}
+
+	cases := make([]reflect.SelectCase, slice.Len())
+	for i := range cases {
+		cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: slice.Index(i)}
+	}
+	return cases, ""
+}
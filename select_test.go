@@ -0,0 +1,160 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSelectRecv(t *testing.T) {
+	c1, c2 := make(chan int, 1), make(chan int, 1)
+	c2 <- 3
+	testSelectRecv([]chan int{c1, c2}, 1, 3, true, nil, t)
+
+	close(c1)
+	testSelectRecv([]chan int{c1, nil}, 0, 0, false, nil, t)
+
+	var r1 <-chan string = make(chan string, 1)
+	s := make(chan string, 1)
+	s <- "a"
+	testSelectRecv([]<-chan string{r1, s}, 1, "a", true, nil, t)
+
+	testSelectRecv(3, 0, nil, false, "illegal.SelectRecv: passed non-slice value", t)
+	testSelectRecv([]int{}, 0, nil, false, "illegal.SelectRecv: slice element type int is not a receivable channel type", t)
+	testSelectRecv([]chan<- int{}, 0, nil, false, "illegal.SelectRecv: slice element type chan<- int is not a receivable channel type", t)
+}
+
+func testSelectRecv(chans interface{}, chosen int, value interface{}, ok bool, err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+
+	c, v, o := SelectRecv(chans)
+	if c != chosen || !reflect.DeepEqual(v, value) || o != ok {
+		t.Errorf("Expected (%v, %v, %v); got (%v, %v, %v)", chosen, value, ok, c, v, o)
+	}
+}
+
+func TestSelectRecvContext(t *testing.T) {
+	c := make(chan int, 1)
+	c <- 1
+	chosen, v, ok, err := SelectRecvContext(context.Background(), []chan int{nil, c})
+	if chosen != 1 || v != 1 || !ok || err != nil {
+		t.Errorf("Expected (1, 1, true, <nil>); got (%v, %v, %v, %v)", chosen, v, ok, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	chosen, v, ok, err = SelectRecvContext(ctx, []chan int{c})
+	if chosen != -1 || v != nil || ok || err != context.DeadlineExceeded {
+		t.Errorf("Expected (-1, <nil>, false, %v); got (%v, %v, %v, %v)", context.DeadlineExceeded, chosen, v, ok, err)
+	}
+
+	func() {
+		expect := "illegal.SelectRecvContext: passed non-slice value"
+		defer func() {
+			if r := recover(); r != expect {
+				t.Errorf("Expected error %v; got %v", expect, r)
+			}
+		}()
+		SelectRecvContext(context.Background(), 3)
+	}()
+
+	func() {
+		expect := "illegal.SelectRecvContext: passed nil Context"
+		defer func() {
+			if r := recover(); r != expect {
+				t.Errorf("Expected error %v; got %v", expect, r)
+			}
+		}()
+		SelectRecvContext(nil, []chan int{})
+	}()
+}
+
+func TestMerge(t *testing.T) {
+	chans := make([]chan int, 3)
+	for i := range chans {
+		chans[i] = make(chan int)
+		go func(c chan int, i int) {
+			for j := 0; j < 3; j++ {
+				c <- i*3 + j
+			}
+			close(c)
+		}(chans[i], i)
+	}
+
+	out, ok := Merge(append(chans, nil)).(<-chan int)
+	if !ok {
+		t.Fatalf("Expected <-chan int; got %T", out)
+	}
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	sort.Ints(got)
+	if expect := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v; got %v", expect, got)
+	}
+
+	// No channels should close immediately
+	if _, ok := <-Merge([]chan string(nil)).(<-chan string); ok {
+		t.Errorf("Expected closed channel")
+	}
+
+	func() {
+		expect := "illegal.Merge: slice element type string is not a receivable channel type"
+		defer func() {
+			if r := recover(); r != expect {
+				t.Errorf("Expected error %v; got %v", expect, r)
+			}
+		}()
+		Merge([]string{})
+	}()
+}
+
+func TestMergeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	out := MergeContext(ctx, []chan int{in}).(<-chan int)
+
+	in <- 1
+	if v := <-out; v != 1 {
+		t.Errorf("Expected 1; got %v", v)
+	}
+
+	// The goroutine is now blocked sending on out,
+	// which nobody is receiving from; canceling
+	// should make it close out
+	in <- 2
+	cancel()
+	closed := make(chan struct{})
+	go func() {
+		for range out {
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected output channel to be closed after cancellation")
+	}
+
+	func() {
+		expect := "illegal.MergeContext: passed nil Context"
+		defer func() {
+			if r := recover(); r != expect {
+				t.Errorf("Expected error %v; got %v", expect, r)
+			}
+		}()
+		MergeContext(nil, []chan int{in})
+	}()
+}
@@ -0,0 +1,261 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// A FuncSignature describes a function's parameters
+// and results, and, if its source is available,
+// their names and the function's documentation.
+type FuncSignature struct {
+	// The function's name as reported by the
+	// runtime (for example, "main.add" or
+	// "main.main.func1" for closures).
+	Name string
+	Type reflect.Type
+
+	Params  []Param
+	Results []Param
+
+	// Whether the function is variadic. If so,
+	// the type of the last parameter is a slice.
+	Variadic bool
+
+	// Whether the function's source was found.
+	// If not, all parameter and result names
+	// are empty, as is Doc.
+	Source bool
+
+	// The location of the function's source
+	// according to the runtime.
+	File string
+	Line int
+
+	// The function's doc comment (without
+	// comment markers), if any.
+	Doc string
+}
+
+// A Param is a parameter or result of a function.
+// Name is empty if it is unnamed, or if the
+// function's source could not be found.
+type Param struct {
+	Name string
+	Type reflect.Type
+}
+
+// String returns the signature as a function
+// type, including parameter names if known:
+//
+//	func(a int, b ...string) (n int, err error)
+func (s *FuncSignature) String() string {
+	params := make([]string, len(s.Params))
+	for i, p := range s.Params {
+		typ := p.Type.String()
+		if s.Variadic && i == len(s.Params)-1 {
+			typ = "..." + p.Type.Elem().String()
+		}
+		params[i] = strings.TrimSpace(p.Name + " " + typ)
+	}
+	results := make([]string, len(s.Results))
+	named := false
+	for i, r := range s.Results {
+		results[i] = strings.TrimSpace(r.Name + " " + r.Type.String())
+		named = named || r.Name != ""
+	}
+
+	str := "func(" + strings.Join(params, ", ") + ")"
+	switch {
+	case len(results) == 1 && !named:
+		str += " " + results[0]
+	case len(results) > 0:
+		str += " (" + strings.Join(results, ", ") + ")"
+	}
+	return str
+}
+
+// Signature describes fn's signature. Parameter
+// and result names and the doc comment are found by
+// parsing fn's source file, which must be available
+// at the path recorded in the binary. This is not
+// always possible; in particular, it fails for
+// method values (such as t.Method), and for
+// closures which cannot be distinguished from
+// other closures declared on the same line.
+//
+// Signature panics if fn is not a function.
+func Signature(fn interface{}) *FuncSignature {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Signature: passed non-function value")
+	}
+	fType := f.Type()
+	s := &FuncSignature{
+		Type:     fType,
+		Params:   make([]Param, fType.NumIn()),
+		Results:  make([]Param, fType.NumOut()),
+		Variadic: fType.IsVariadic(),
+	}
+	for i := range s.Params {
+		s.Params[i].Type = fType.In(i)
+	}
+	for i := range s.Results {
+		s.Results[i].Type = fType.Out(i)
+	}
+
+	rf := runtime.FuncForPC(f.Pointer())
+	if rf == nil {
+		return s
+	}
+	s.Name = rf.Name()
+	s.File, s.Line = rf.FileLine(rf.Entry())
+	s.addSource()
+	return s
+}
+
+// addSource fills in the names and doc
+// from the declaration of s's function.
+func (s *FuncSignature) addSource() {
+	n := s.findSource()
+	if n == nil {
+		return
+	}
+	var typ *ast.FuncType
+	if decl, ok := n.(*ast.FuncDecl); ok {
+		typ = decl.Type
+		s.Doc = decl.Doc.Text()
+	} else {
+		typ = n.(*ast.FuncLit).Type
+	}
+	params, results, _ := s.names(n, typ)
+	for i := range params {
+		s.Params[i].Name = params[i]
+	}
+	for i := range results {
+		s.Results[i].Name = results[i]
+	}
+	s.Source = true
+}
+
+// findSource returns the declaration of s's
+// function (an *ast.FuncDecl or *ast.FuncLit)
+// in s.File, or nil if it can't be found.
+func (s *FuncSignature) findSource() ast.Node {
+	file, fset := parseSourceFile(s.File)
+	if file == nil {
+		return nil
+	}
+
+	// The runtime reports a line within the
+	// function (usually that of the first
+	// statement), so look for the declarations
+	// which span that line and have the right
+	// shape.
+	var candidates []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		var typ *ast.FuncType
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			typ = n.Type
+		case *ast.FuncLit:
+			typ = n.Type
+		default:
+			return true
+		}
+		if fset.Position(n.Pos()).Line <= s.Line && s.Line <= fset.Position(n.End()).Line {
+			if _, _, ok := s.names(n, typ); ok {
+				candidates = append(candidates, n)
+			}
+		}
+		return true
+	})
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// If the candidates are nested, take the
+	// innermost (which is found last). s.Line is
+	// the line of the function's entry, which is
+	// usually that of its func keyword, so this is
+	// only wrong if a nested function of the same
+	// shape spans that line too (for example, if
+	// both are written on a single line). If the
+	// candidates aren't nested, we can't tell them
+	// apart, and refuse to guess.
+	n := candidates[len(candidates)-1]
+	for _, c := range candidates[:len(candidates)-1] {
+		if c.Pos() > n.Pos() || c.End() < n.End() {
+			return nil
+		}
+	}
+	return n
+}
+
+// names returns the parameter and result names
+// declared by n, and whether they match the
+// number of parameters and results in s.
+func (s *FuncSignature) names(n ast.Node, typ *ast.FuncType) (params, results []string, ok bool) {
+	params = fieldNames(typ.Params)
+	// For method expressions (such as T.Method),
+	// the receiver is the first parameter
+	if decl, ok := n.(*ast.FuncDecl); ok && decl.Recv != nil && len(params)+1 == len(s.Params) {
+		params = append(fieldNames(decl.Recv), params...)
+	}
+	results = fieldNames(typ.Results)
+	return params, results, len(params) == len(s.Params) && len(results) == len(s.Results)
+}
+
+func fieldNames(list *ast.FieldList) []string {
+	var names []string
+	if list == nil {
+		return names
+	}
+	for _, f := range list.List {
+		if len(f.Names) == 0 {
+			names = append(names, "")
+		}
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+// Parsed source files are cached, since
+// callers such as flag generators tend to
+// inspect many functions in the same file.
+var sourceFiles struct {
+	sync.Mutex
+	files map[string]*ast.File
+	fset  *token.FileSet
+}
+
+func parseSourceFile(path string) (*ast.File, *token.FileSet) {
+	sourceFiles.Lock()
+	defer sourceFiles.Unlock()
+	if sourceFiles.files == nil {
+		sourceFiles.files = make(map[string]*ast.File)
+		sourceFiles.fset = token.NewFileSet()
+	}
+	file, ok := sourceFiles.files[path]
+	if !ok {
+		// Failures are cached as nil
+		var err error
+		file, err = parser.ParseFile(sourceFiles.fset, path, nil, parser.ParseComments)
+		if err != nil {
+			file = nil
+		}
+		sourceFiles.files[path] = file
+	}
+	return file, sourceFiles.fset
+}
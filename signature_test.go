@@ -0,0 +1,70 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// signatureTestFunc is documented.
+func signatureTestFunc(a, b int,
+	rest ...string) (n int, err error) {
+	return 0, nil
+}
+
+type signatureTestType struct{}
+
+func (s signatureTestType) Method(x float64) bool { return false }
+
+func TestSignature(t *testing.T) {
+	s := Signature(signatureTestFunc)
+	testSignature(s, "github.com/joshlf13/illegal.signatureTestFunc", true, "signatureTestFunc is documented.\n",
+		"func(a int, b int, rest ...string) (n int, err error)", t)
+	if !s.Variadic || s.Params[2].Type != reflect.TypeOf([]string{}) || s.Results[1].Type != errorType {
+		t.Errorf("Unexpected signature %+v", s)
+	}
+	if !strings.HasSuffix(s.File, "signature_test.go") {
+		t.Errorf("Expected file signature_test.go; got %v", s.File)
+	}
+
+	f := func(x int, _ string) {}
+	testSignature(Signature(f), "github.com/joshlf13/illegal.TestSignature.func1", true, "", "func(x int, _ string)", t)
+	testSignature(Signature(func() {}), "github.com/joshlf13/illegal.TestSignature.func2", true, "", "func()", t)
+	testSignature(Signature(signatureTestType.Method), "github.com/joshlf13/illegal.signatureTestType.Method", true, "",
+		"func(s illegal.signatureTestType, x float64) bool", t)
+	testSignature(Signature(signatureTestType{}.Method), "github.com/joshlf13/illegal.signatureTestType.Method-fm", false, "",
+		"func(float64) bool", t)
+
+	// Two closures on the same line with the
+	// same shape can't be told apart
+	g, h := func(a int) {}, func(b int) {}
+	testSignature(Signature(g), "github.com/joshlf13/illegal.TestSignature.func3", false, "", "func(int)", t)
+	testSignature(Signature(h), "github.com/joshlf13/illegal.TestSignature.func4", false, "", "func(int)", t)
+
+	s = Signature(fmt.Sprintf)
+	if s.Source && s.Params[0].Name != "format" {
+		t.Errorf("Expected parameter name format; got %v", s.Params[0].Name)
+	}
+
+	testWrapPanic(func() { Signature(3) }, "illegal.Signature: passed non-function value", t)
+}
+
+func testSignature(s *FuncSignature, name string, source bool, doc, str string, t *testing.T) {
+	if s.Name != name {
+		t.Errorf("Expected name %v; got %v", name, s.Name)
+	}
+	if s.Source != source {
+		t.Errorf("Expected Source to be %v for %v; got %v", source, name, s.Source)
+	}
+	if s.Doc != doc {
+		t.Errorf("Expected doc %q for %v; got %q", doc, name, s.Doc)
+	}
+	if s.String() != str {
+		t.Errorf("Expected %q for %v; got %q", str, name, s.String())
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// A Call records the arguments and results
+// of a single call to a spy. For variadic
+// functions, the variadic arguments are
+// recorded as a single slice. If the call
+// panicked, Results is nil.
+type Call struct {
+	Args    []interface{}
+	Results []interface{}
+}
+
+// A Recorder records the calls made to a
+// spy created by Spy. It is safe for
+// concurrent use.
+type Recorder struct {
+	fn    reflect.Value
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Count returns the number of calls
+// which have been made to the spy.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+// Calls returns the calls which have been
+// made to the spy, in the order in which
+// they were made.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Wraps returns whether the spy delegates to
+// fn, as determined by FuncEqual.
+//
+// Wraps panics if fn is not a function.
+func (r *Recorder) Wraps(fn interface{}) bool {
+	if reflect.TypeOf(fn) == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		panic("illegal.Recorder.Wraps: passed non-function value")
+	}
+	return FuncEqual(r.fn.Interface(), fn)
+}
+
+// Spy returns a function of the same type as fn
+// which calls fn, and records the arguments and
+// results of each call in the returned Recorder.
+//
+// Spy panics if fn is not a function.
+func Spy(fn interface{}) (spy interface{}, rec *Recorder) {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Spy: passed non-function value")
+	}
+	rec = &Recorder{fn: f}
+	spy = reflect.MakeFunc(f.Type(), func(args []reflect.Value) (results []reflect.Value) {
+		call := Call{Args: interfaces(args)}
+		defer func() {
+			rec.mu.Lock()
+			rec.calls = append(rec.calls, call)
+			rec.mu.Unlock()
+		}()
+		results = callFunc(f, args)
+		call.Results = interfaces(results)
+		return results
+	}).Interface()
+	return spy, rec
+}
+
+// Stub returns a function of the same type as
+// example (which may be a nil function value,
+// such as (func(int) error)(nil)), which ignores
+// its arguments, and returns each of results in
+// turn, one per call. Once results have been
+// exhausted, the last is returned for all
+// subsequent calls. If results is empty, the
+// function returns zero values.
+//
+// For functions with a single result, each
+// element of results is the value to return.
+// For functions with zero or multiple results,
+// each element must be a []interface{} holding
+// one value per result. Each value is used as-is
+// if it is assignable to the corresponding result
+// type, and converted if it is convertible (as
+// with Invoke). nil stands for the zero value
+// of result types which can be nil.
+//
+// Stub panics if example is not a function, or
+// if results do not match its result types.
+func Stub(example interface{}, results ...interface{}) interface{} {
+	fType := reflect.TypeOf(example)
+	if fType == nil || fType.Kind() != reflect.Func {
+		panic("illegal.Stub: passed non-function value")
+	}
+
+	sets := make([][]reflect.Value, len(results))
+	for i, r := range results {
+		set := []interface{}{r}
+		if fType.NumOut() != 1 {
+			var ok bool
+			set, ok = r.([]interface{})
+			if !ok {
+				panic("illegal.Stub: result set " + strconv.Itoa(i) + " is not a []interface{}")
+			}
+		}
+		if len(set) != fType.NumOut() {
+			panic("illegal.Stub: result set " + strconv.Itoa(i) + " has " + strconv.Itoa(len(set)) + " values; want " + strconv.Itoa(fType.NumOut()))
+		}
+		sets[i] = make([]reflect.Value, len(set))
+		for j, v := range set {
+			val, _, ok := convertArg(v, fType.Out(j))
+			if !ok {
+				panic("illegal.Stub: cannot use value " + strconv.Itoa(j) + " of result set " + strconv.Itoa(i) +
+					" (type " + typeString(reflect.TypeOf(v)) + ") as type " + fType.Out(j).String())
+			}
+			sets[i][j] = val
+		}
+	}
+	if len(sets) == 0 {
+		zero := make([]reflect.Value, fType.NumOut())
+		for i := range zero {
+			zero[i] = reflect.Zero(fType.Out(i))
+		}
+		sets = append(sets, zero)
+	}
+
+	var mu sync.Mutex
+	next := 0
+	return reflect.MakeFunc(fType, func(args []reflect.Value) []reflect.Value {
+		mu.Lock()
+		defer mu.Unlock()
+		ret := sets[next]
+		if next < len(sets)-1 {
+			next++
+		}
+		return ret
+	}).Interface()
+}
+
+func interfaces(vals []reflect.Value) []interface{} {
+	ret := make([]interface{}, len(vals))
+	for i, v := range vals {
+		ret[i] = v.Interface()
+	}
+	return ret
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSpy(t *testing.T) {
+	s, rec := Spy(wrapTestDivide)
+	divide := s.(func(int, int) (int, error))
+	divide(6, 3)
+	divide(1, 0)
+
+	if n := rec.Count(); n != 2 {
+		t.Errorf("Expected 2 calls; got %v", n)
+	}
+	expect := []Call{
+		{Args: []interface{}{6, 3}, Results: []interface{}{2, nil}},
+		{Args: []interface{}{1, 0}, Results: []interface{}{0, errors.New("division by zero")}},
+	}
+	if calls := rec.Calls(); !reflect.DeepEqual(calls, expect) {
+		t.Errorf("Expected %v; got %v", expect, calls)
+	}
+	if !rec.Wraps(wrapTestDivide) {
+		t.Errorf("Expected spy to wrap wrapTestDivide")
+	}
+	if rec.Wraps(wrapTestAdd) {
+		t.Errorf("Expected spy not to wrap wrapTestAdd")
+	}
+
+	// Variadic arguments are recorded as a slice,
+	// and panicking calls are recorded without results
+	s, rec = Spy(func(format string, args ...interface{}) string { panic(fmt.Sprintf(format, args...)) })
+	testWrapPanic(func() { s.(func(string, ...interface{}) string)("%d", 1) }, "1", t)
+	expect = []Call{{Args: []interface{}{"%d", []interface{}{1}}}}
+	if calls := rec.Calls(); !reflect.DeepEqual(calls, expect) {
+		t.Errorf("Expected %v; got %v", expect, calls)
+	}
+
+	testWrapPanic(func() { Spy(3) }, "illegal.Spy: passed non-function value", t)
+	testWrapPanic(func() { rec.Wraps(3) }, "illegal.Recorder.Wraps: passed non-function value", t)
+}
+
+func TestStub(t *testing.T) {
+	next := Stub((func() int)(nil), 1, int8(2)).(func() int)
+	for _, expect := range []int{1, 2, 2} {
+		if n := next(); n != expect {
+			t.Errorf("Expected %v; got %v", expect, n)
+		}
+	}
+
+	read := Stub(io.Reader.Read, []interface{}{3, nil}, []interface{}{0, io.EOF}).(func(io.Reader, []byte) (int, error))
+	if n, err := read(nil, nil); n != 3 || err != nil {
+		t.Errorf("Expected (3, <nil>); got (%v, %v)", n, err)
+	}
+	if n, err := read(nil, nil); n != 0 || err != io.EOF {
+		t.Errorf("Expected (0, EOF); got (%v, %v)", n, err)
+	}
+
+	zero := Stub(wrapTestDivide).(func(int, int) (int, error))
+	if n, err := zero(1, 2); n != 0 || err != nil {
+		t.Errorf("Expected (0, <nil>); got (%v, %v)", n, err)
+	}
+	Stub(func() {}, []interface{}{}).(func())()
+
+	testWrapPanic(func() { Stub(3) }, "illegal.Stub: passed non-function value", t)
+	testWrapPanic(func() { Stub(nil) }, "illegal.Stub: passed non-function value", t)
+	testWrapPanic(func() { Stub(wrapTestDivide, 1) }, "illegal.Stub: result set 0 is not a []interface{}", t)
+	testWrapPanic(func() { Stub(wrapTestDivide, []interface{}{1}) }, "illegal.Stub: result set 0 has 1 values; want 2", t)
+	testWrapPanic(func() { Stub(wrapTestAdd, 1, "a") }, "illegal.Stub: cannot use value 0 of result set 1 (type string) as type int", t)
+	testWrapPanic(func() { Stub(wrapTestAdd, nil) }, "illegal.Stub: cannot use value 0 of result set 0 (type <nil>) as type int", t)
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// StringBytes returns a []byte which shares its
+// memory with s, without copying. The returned
+// slice must never be modified; strings are
+// immutable, and the compiler and runtime rely
+// on this (the bytes of a string literal, for
+// example, may reside in read-only memory).
+//
+// If s is empty, StringBytes returns a nil slice.
+//
+// When built with the illegaldebug build tag,
+// the bytes are checksummed, and modifying them
+// is detected; see CheckStrings.
+func StringBytes(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+	trackView(b, "StringBytes")
+	return b
+}
+
+// BytesString returns a string which shares its
+// memory with b, without copying. After calling
+// BytesString, b must not be modified for as long
+// as the returned string (or any string derived
+// from it) is in use.
+//
+// When built with the illegaldebug build tag,
+// the bytes are checksummed, and modifying them
+// is detected; see CheckStrings. In such builds,
+// b must not be reused until it is forgotten,
+// even if the returned string is no longer in
+// use, since that can't be told apart from
+// modifying memory which a string still refers
+// to; copy b with string(b) instead if it is
+// to be reused soon.
+func BytesString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	trackView(b, "BytesString")
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// CheckStrings verifies that no memory shared
+// between a string and a []byte by StringBytes
+// or BytesString has been modified since it was
+// shared, and panics if it has. The panic message
+// identifies the calls which shared the memory.
+// Each modification is only reported once.
+//
+// CheckStrings only has an effect when built with
+// the illegaldebug build tag. In such builds, the
+// same check is also made automatically once per
+// garbage collection cycle, and modifications it
+// finds are passed to the function set by
+// SetStringsHandler. Only the 1024 most recent
+// calls to StringBytes and BytesString are
+// tracked, and the memory they shared is kept
+// alive until it is forgotten.
+func CheckStrings() {
+	if msgs := checkViews(); len(msgs) > 0 {
+		panic(strings.Join(msgs, "\n"))
+	}
+}
+
+var stringsHandler atomic.Pointer[func(msg string)]
+
+// SetStringsHandler sets the function which is
+// called with a message for each modification
+// found by the check which CheckStrings describes
+// when it is made automatically. The function is
+// called on the runtime's finalizer goroutine, so
+// it should not block, and if it panics, the
+// program crashes. By default, or if handler is
+// nil, the message is written to standard error.
+func SetStringsHandler(handler func(msg string)) {
+	if handler == nil {
+		stringsHandler.Store(nil)
+		return
+	}
+	stringsHandler.Store(&handler)
+}
+
+// reportStrings passes each of msgs to the
+// function set by SetStringsHandler.
+func reportStrings(msgs []string) {
+	handler := func(msg string) { fmt.Fprintln(os.Stderr, msg) }
+	if h := stringsHandler.Load(); h != nil {
+		handler = *h
+	}
+	for _, msg := range msgs {
+		handler(msg)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build illegaldebug
+
+package illegal
+
+import (
+	"fmt"
+	"hash/crc32"
+	"runtime"
+	"sync"
+)
+
+// The number of most recent views which are
+// tracked. Older views are forgotten, and
+// mutations to them go undetected.
+//
+// We can't use weak pointers to track an
+// unbounded number of views without keeping
+// them alive, since the runtime can't make
+// weak pointers to memory outside the heap
+// (such as the bytes of string literals).
+// Nor can we tell when a string returned by
+// BytesString is no longer in use, since it
+// shares its memory with b, which may well
+// still be; see the reuse rule documented
+// there.
+// Instead, we keep the most recent views
+// alive, and bound the cost of doing so.
+const maxViews = 1024
+
+// A view is memory which has been shared
+// between a string and a []byte.
+type view struct {
+	b      []byte
+	sum    uint32
+	caller string // "illegal.BytesString called at file:line"
+}
+
+var views struct {
+	sync.Mutex
+	ring [maxViews]view
+	next int
+}
+
+var startGCHook sync.Once
+
+func trackView(b []byte, fname string) {
+	v := view{b: b, sum: crc32.ChecksumIEEE(b), caller: "illegal." + fname}
+	if _, file, line, ok := runtime.Caller(2); ok {
+		v.caller += fmt.Sprintf(" called at %s:%d", file, line)
+	}
+
+	views.Lock()
+	views.ring[views.next] = v
+	views.next = (views.next + 1) % maxViews
+	views.Unlock()
+
+	startGCHook.Do(func() { setGCHook(new(gcSentinel)) })
+}
+
+// checkViews returns a message for each view
+// whose memory has been modified, and forgets
+// those views so that each modification is
+// only reported once.
+func checkViews() (msgs []string) {
+	views.Lock()
+	defer views.Unlock()
+	for i := range views.ring {
+		v := &views.ring[i]
+		if v.b != nil && crc32.ChecksumIEEE(v.b) != v.sum {
+			msgs = append(msgs, "illegal: memory shared between string and []byte was modified (shared by "+v.caller+")")
+			*v = view{}
+		}
+	}
+	return msgs
+}
+
+// gcSentinel contains a pointer so that it
+// is never batched with other objects by
+// the tiny allocator, which could keep it
+// from being collected.
+type gcSentinel struct {
+	_ *byte
+}
+
+// setGCHook arranges for checkViews to be
+// called once per garbage collection cycle
+// by resurrecting s in its own finalizer.
+// Panicking there would crash the program,
+// so modifications are passed to the
+// handler instead.
+func setGCHook(s *gcSentinel) {
+	runtime.SetFinalizer(s, func(s *gcSentinel) {
+		setGCHook(s)
+		reportStrings(checkViews())
+	})
+}
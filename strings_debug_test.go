@@ -0,0 +1,87 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build illegaldebug
+
+package illegal
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckStrings(t *testing.T) {
+	// Keep the GC from finding the mutation
+	// before we call CheckStrings ourselves
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	// Unmodified bytes should pass
+	b := []byte("hello, world")
+	BytesString(b)
+	testCheckStrings("", t)
+
+	b[0] = 'j'
+	testCheckStrings("illegal: memory shared between string and []byte was modified (shared by illegal.BytesString called at ", t)
+
+	// Each mutation is only reported once
+	testCheckStrings("", t)
+
+	// Reusing a buffer is reported even once
+	// the string is no longer in use
+	func() {
+		s := BytesString(b)
+		_ = s
+	}()
+	copy(b, "goodbye, all")
+	testCheckStrings("illegal: memory shared between string and []byte was modified (shared by illegal.BytesString called at ", t)
+}
+
+func TestStringsHandler(t *testing.T) {
+	msgs := make(chan string, 1)
+	SetStringsHandler(func(msg string) { msgs <- msg })
+	defer SetStringsHandler(nil)
+
+	b := []byte("hello, world")
+	BytesString(b)
+	b[0] = 'j'
+
+	// The mutation should be passed to the handler
+	// rather than panicking in the finalizer
+	deadline := time.After(10 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case msg := <-msgs:
+			prefix := "illegal: memory shared between string and []byte was modified (shared by illegal.BytesString called at "
+			if !strings.HasPrefix(msg, prefix) {
+				t.Errorf("Expected message with prefix %q; got %q", prefix, msg)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Expected the handler to be called")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func testCheckStrings(prefix string, t *testing.T) {
+	defer func() {
+		r := recover()
+		if prefix == "" {
+			if r != nil {
+				t.Errorf("Expected no error; got %v", r)
+			}
+			return
+		}
+		str, _ := r.(string)
+		if !strings.HasPrefix(str, prefix) {
+			t.Errorf("Expected error with prefix %q; got %v", prefix, r)
+		}
+	}()
+
+	CheckStrings()
+}
@@ -0,0 +1,51 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestStringBytes(t *testing.T) {
+	testStringBytes("", t)
+	testStringBytes("a", t)
+	testStringBytes("hello, world", t)
+	testStringBytes(string([]byte{0, 1, 2, 255}), t)
+}
+
+func testStringBytes(s string, t *testing.T) {
+	b := StringBytes(s)
+	if string(b) != s {
+		t.Errorf("Expected %q; got %q", s, b)
+	}
+	if len(s) == 0 {
+		if b != nil {
+			t.Errorf("Expected nil slice; got %v", b)
+		}
+		return
+	}
+	if unsafe.SliceData(b) != unsafe.StringData(s) {
+		t.Errorf("Expected StringBytes(%q) not to copy", s)
+	}
+}
+
+func TestBytesString(t *testing.T) {
+	testBytesString(nil, t)
+	testBytesString([]byte{}, t)
+	testBytesString([]byte("a"), t)
+	testBytesString([]byte("hello, world"), t)
+	testBytesString([]byte{0, 1, 2, 255}, t)
+}
+
+func testBytesString(b []byte, t *testing.T) {
+	s := BytesString(b)
+	if s != string(b) {
+		t.Errorf("Expected %q; got %q", b, s)
+	}
+	if len(b) > 0 && unsafe.StringData(s) != unsafe.SliceData(b) {
+		t.Errorf("Expected BytesString(%q) not to copy", b)
+	}
+}
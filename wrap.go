@@ -0,0 +1,174 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Wrap returns a function of the same type as fn
+// which calls before (if it is non-nil) with the
+// arguments it was called with, then calls fn with
+// those arguments, then calls after (if it is
+// non-nil) with fn's results, and finally returns
+// those results. before and after may modify the
+// arguments and results in place (so long as each
+// value remains of the same type). For variadic
+// functions, the variadic arguments are passed
+// to before as a single slice.
+//
+// Since the returned function is of the same type
+// as fn, it can be type asserted back to that type,
+// or passed anywhere that fn could be (such as to
+// generics.Map).
+//
+// Wrap panics if fn is not a function.
+func Wrap(fn interface{}, before func(args []reflect.Value), after func(results []reflect.Value)) interface{} {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Wrap: passed non-function value")
+	}
+	return reflect.MakeFunc(f.Type(), func(args []reflect.Value) []reflect.Value {
+		if before != nil {
+			before(args)
+		}
+		results := callFunc(f, args)
+		if after != nil {
+			after(results)
+		}
+		return results
+	}).Interface()
+}
+
+// Timed returns a function of the same type as fn
+// which calls fn, and then calls report with the
+// duration of the call, even if fn panics.
+//
+// Timed panics if fn is not a function, or
+// if report is nil.
+func Timed(fn interface{}, report func(d time.Duration)) interface{} {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Timed: passed non-function value")
+	}
+	if report == nil {
+		panic("illegal.Timed: passed nil report function")
+	}
+	return reflect.MakeFunc(f.Type(), func(args []reflect.Value) []reflect.Value {
+		start := time.Now()
+		defer func() { report(time.Since(start)) }()
+		return callFunc(f, args)
+	}).Interface()
+}
+
+// Logged returns a function of the same type as fn
+// which calls fn, and then calls logf (which may be,
+// for example, log.Printf) with a description of the
+// call, including fn's name, its arguments, and
+// its results:
+//
+//	main.add(1, 2) = (3)
+//
+// Logged panics if fn is not a function, or
+// if logf is nil.
+func Logged(fn interface{}, logf func(format string, args ...interface{})) interface{} {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Logged: passed non-function value")
+	}
+	if logf == nil {
+		panic("illegal.Logged: passed nil logf function")
+	}
+	name := funcName(f)
+	return reflect.MakeFunc(f.Type(), func(args []reflect.Value) []reflect.Value {
+		results := callFunc(f, args)
+		logf("%s(%s) = (%s)", name, valuesString(args), valuesString(results))
+		return results
+	}).Interface()
+}
+
+// Recovered returns a function of the same type as
+// fn which calls fn, and if fn panics, recovers and
+// returns zero values for all of fn's results but
+// the last, and an error describing the panic for
+// the last.
+//
+// Recovered panics if fn is not a function, or if
+// fn's last result is not of type error.
+func Recovered(fn interface{}) interface{} {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Recovered: passed non-function value")
+	}
+	fType := f.Type()
+	if fType.NumOut() == 0 || fType.Out(fType.NumOut()-1) != errorType {
+		panic("illegal.Recovered: function's last result is not of type error")
+	}
+	name := funcName(f)
+	return reflect.MakeFunc(fType, func(args []reflect.Value) (results []reflect.Value) {
+		defer func() {
+			if r := recover(); r != nil {
+				results = make([]reflect.Value, fType.NumOut())
+				for i := range results {
+					results[i] = reflect.Zero(fType.Out(i))
+				}
+				err := fmt.Errorf("%s: recovered from panic: %v", name, r)
+				results[len(results)-1] = reflect.ValueOf(&err).Elem()
+			}
+		}()
+		return callFunc(f, args)
+	}).Interface()
+}
+
+// Counted returns a function of the same type as
+// fn which calls fn, and a function which returns
+// the number of times that the returned function
+// has been called. Both are safe for concurrent use.
+//
+// Counted panics if fn is not a function.
+func Counted(fn interface{}) (counted interface{}, count func() int64) {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic("illegal.Counted: passed non-function value")
+	}
+	var n int64
+	counted = reflect.MakeFunc(f.Type(), func(args []reflect.Value) []reflect.Value {
+		atomic.AddInt64(&n, 1)
+		return callFunc(f, args)
+	}).Interface()
+	return counted, func() int64 { return atomic.LoadInt64(&n) }
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// callFunc calls f with args as received by
+// a function created with reflect.MakeFunc,
+// which passes variadic arguments as a slice.
+func callFunc(f reflect.Value, args []reflect.Value) []reflect.Value {
+	if f.Type().IsVariadic() {
+		return f.CallSlice(args)
+	}
+	return f.Call(args)
+}
+
+func funcName(f reflect.Value) string {
+	if rf := runtime.FuncForPC(f.Pointer()); rf != nil {
+		return rf.Name()
+	}
+	return f.Type().String()
+}
+
+func valuesString(vals []reflect.Value) string {
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = fmt.Sprintf("%#v", v)
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,136 @@
+// Copyright 2013 The Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package illegal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func wrapTestAdd(a, b int) int { return a + b }
+
+func wrapTestDivide(a, b int) (int, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+
+func TestWrap(t *testing.T) {
+	var before, after []interface{}
+	w := Wrap(wrapTestAdd, func(args []reflect.Value) {
+		before = append(before, args[0].Interface(), args[1].Interface())
+		args[1] = reflect.ValueOf(10)
+	}, func(results []reflect.Value) {
+		after = append(after, results[0].Interface())
+	})
+	add, ok := w.(func(int, int) int)
+	if !ok {
+		t.Fatalf("Expected func(int, int) int; got %T", w)
+	}
+	if r := add(1, 2); r != 11 {
+		t.Errorf("Expected 11; got %v", r)
+	}
+	if expect := []interface{}{1, 2}; !reflect.DeepEqual(before, expect) {
+		t.Errorf("Expected before to see %v; got %v", expect, before)
+	}
+	if expect := []interface{}{11}; !reflect.DeepEqual(after, expect) {
+		t.Errorf("Expected after to see %v; got %v", expect, after)
+	}
+
+	// Variadic functions and nil callbacks
+	sprint := Wrap(fmt.Sprint, nil, nil).(func(...interface{}) string)
+	if s := sprint("a", 1); s != "a1" {
+		t.Errorf("Expected a1; got %v", s)
+	}
+
+	testWrapPanic(func() { Wrap(3, nil, nil) }, "illegal.Wrap: passed non-function value", t)
+}
+
+func TestTimed(t *testing.T) {
+	var d time.Duration
+	sleep := Timed(time.Sleep, func(dur time.Duration) { d = dur }).(func(time.Duration))
+	sleep(time.Millisecond)
+	if d < time.Millisecond {
+		t.Errorf("Expected duration of at least %v; got %v", time.Millisecond, d)
+	}
+
+	// Calls which panic should be timed too
+	d = 0
+	fail := Timed(func() { time.Sleep(time.Millisecond); panic("fail") }, func(dur time.Duration) { d = dur }).(func())
+	testWrapPanic(fail, "fail", t)
+	if d < time.Millisecond {
+		t.Errorf("Expected duration of at least %v; got %v", time.Millisecond, d)
+	}
+
+	testWrapPanic(func() { Timed(3, nil) }, "illegal.Timed: passed non-function value", t)
+	testWrapPanic(func() { Timed(wrapTestAdd, nil) }, "illegal.Timed: passed nil report function", t)
+}
+
+func TestLogged(t *testing.T) {
+	var logs []string
+	logf := func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }
+
+	Logged(wrapTestAdd, logf).(func(int, int) int)(1, 2)
+	Logged(strings.Repeat, logf).(func(string, int) string)("a", 3)
+	expect := []string{
+		"github.com/joshlf13/illegal.wrapTestAdd(1, 2) = (3)",
+		`strings.Repeat("a", 3) = ("aaa")`,
+	}
+	if !reflect.DeepEqual(logs, expect) {
+		t.Errorf("Expected %q; got %q", expect, logs)
+	}
+
+	testWrapPanic(func() { Logged(3, nil) }, "illegal.Logged: passed non-function value", t)
+	testWrapPanic(func() { Logged(wrapTestAdd, nil) }, "illegal.Logged: passed nil logf function", t)
+}
+
+func TestRecovered(t *testing.T) {
+	divide := Recovered(wrapTestDivide).(func(int, int) (int, error))
+	if q, err := divide(6, 3); q != 2 || err != nil {
+		t.Errorf("Expected (2, <nil>); got (%v, %v)", q, err)
+	}
+	if q, err := divide(6, 0); q != 0 || err == nil || err.Error() != "division by zero" {
+		t.Errorf("Expected (0, division by zero); got (%v, %v)", q, err)
+	}
+
+	f := Recovered(func(s []int) (int, error) { return s[5], nil }).(func([]int) (int, error))
+	q, err := f(nil)
+	expect := "github.com/joshlf13/illegal.TestRecovered.func1: recovered from panic: runtime error: index out of range [5] with length 0"
+	if q != 0 || err == nil || err.Error() != expect {
+		t.Errorf("Expected (0, %v); got (%v, %v)", expect, q, err)
+	}
+
+	testWrapPanic(func() { Recovered(3) }, "illegal.Recovered: passed non-function value", t)
+	testWrapPanic(func() { Recovered(wrapTestAdd) }, "illegal.Recovered: function's last result is not of type error", t)
+	testWrapPanic(func() { Recovered(func() {}) }, "illegal.Recovered: function's last result is not of type error", t)
+}
+
+func TestCounted(t *testing.T) {
+	c, count := Counted(wrapTestAdd)
+	add := c.(func(int, int) int)
+	for i := 0; i < 3; i++ {
+		add(i, i)
+	}
+	if n := count(); n != 3 {
+		t.Errorf("Expected 3 calls; got %v", n)
+	}
+
+	testWrapPanic(func() { Counted(3) }, "illegal.Counted: passed non-function value", t)
+}
+
+func testWrapPanic(f func(), err interface{}, t *testing.T) {
+	defer func() {
+		r := recover()
+		if !reflect.DeepEqual(r, err) {
+			t.Errorf("Expected error %v; got %v", err, r)
+		}
+	}()
+	f()
+}